@@ -0,0 +1,150 @@
+package scrape
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// unknownCompany is the company of contributors no affiliation matches.
+const unknownCompany = "unknown/independent"
+
+// Affiliations maps contributors to the companies they worked for, in the
+// spirit of gitdm's domain-map and email-map files. Every line of an
+// affiliation file maps an email domain, an email address or a @login to a
+// company, optionally until a date:
+//
+//	example.com          Example Inc
+//	jane@example.org     Example Inc < 2021-06-01
+//	jane@example.org     Acme Corp
+//	@jdoe                Acme Corp
+//
+// Lines for the same key with an end date apply up to that date, the line
+// without one applies afterwards.
+type Affiliations struct {
+	domains map[string][]affiliation
+	emails  map[string][]affiliation
+	logins  map[string][]affiliation
+}
+
+// affiliation is a company a contributor worked for until a date. A zero
+// until means to this day.
+type affiliation struct {
+	company string
+	until   time.Time
+}
+
+// ParseAffiliations reads an affiliation file from r.
+func ParseAffiliations(r io.Reader) (*Affiliations, error) {
+	a := &Affiliations{
+		domains: make(map[string][]affiliation),
+		emails:  make(map[string][]affiliation),
+		logins:  make(map[string][]affiliation),
+	}
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := s.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		f := strings.Fields(line)
+		if len(f) == 0 {
+			continue
+		}
+		if len(f) < 2 {
+			return nil, fmt.Errorf("affiliations line %d: missing company", n)
+		}
+		key, rest := strings.ToLower(f[0]), strings.Join(f[1:], " ")
+		af := affiliation{company: rest}
+		if i := strings.Index(rest, "<"); i >= 0 {
+			until, err := time.Parse("2006-01-02", strings.TrimSpace(rest[i+1:]))
+			if err != nil {
+				return nil, fmt.Errorf("affiliations line %d: %v", n, err)
+			}
+			af = affiliation{company: strings.TrimSpace(rest[:i]), until: until}
+		}
+		m := a.domains
+		switch {
+		case strings.HasPrefix(key, "@"):
+			m, key = a.logins, key[1:]
+		case strings.Contains(key, "@"):
+			m = a.emails
+		}
+		m[key] = append(m[key], af)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	for _, m := range []map[string][]affiliation{a.domains, a.emails, a.logins} {
+		for _, afs := range m {
+			sort.SliceStable(afs, func(i, j int) bool {
+				if afs[i].until.IsZero() || afs[j].until.IsZero() {
+					return !afs[i].until.IsZero()
+				}
+				return afs[i].until.Before(afs[j].until)
+			})
+		}
+	}
+	return a, nil
+}
+
+// LoadAffiliations reads the affiliation file at path.
+func LoadAffiliations(path string) (*Affiliations, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseAffiliations(f)
+}
+
+// byEmail reports whether a has email or domain lines, which only apply to
+// contributions that carry the email of their author.
+func (a *Affiliations) byEmail() bool {
+	return a != nil && len(a.domains)+len(a.emails) > 0
+}
+
+// at returns the company of afs at date t.
+func at(afs []affiliation, t time.Time) (string, bool) {
+	for _, af := range afs {
+		if af.until.IsZero() || t.Before(af.until) {
+			return af.company, true
+		}
+	}
+	return "", false
+}
+
+// Company returns the company a contributor worked for at date t. The
+// login is tried first, then the email and finally its domain and parent
+// domains. A nil Affiliations matches nothing.
+func (a *Affiliations) Company(login, email string, t time.Time) (string, bool) {
+	if a == nil {
+		return "", false
+	}
+	if c, ok := at(a.logins[strings.ToLower(login)], t); ok {
+		return c, true
+	}
+	email = strings.ToLower(email)
+	if c, ok := at(a.emails[email], t); ok {
+		return c, true
+	}
+	i := strings.LastIndex(email, "@")
+	if i < 0 {
+		return "", false
+	}
+	for d := email[i+1:]; d != ""; {
+		if c, ok := at(a.domains[d], t); ok {
+			return c, true
+		}
+		j := strings.Index(d, ".")
+		if j < 0 {
+			break
+		}
+		d = d[j+1:]
+	}
+	return "", false
+}
@@ -0,0 +1,76 @@
+package scrape
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testAffiliations = `# domains
+example.com            Example Inc
+eu.example.com         Example Europe
+# dated lines, out of order
+jane@example.org       Acme Corp
+jane@example.org       Example Inc < 2021-06-01
+jane@example.org       Startup < 2019-01-01
+@JDoe                  Acme Corp   # logins ignore case
+@old                   Old Co < 2020-01-01
+`
+
+func TestAffiliationsCompany(t *testing.T) {
+	a, err := ParseAffiliations(strings.NewReader(testAffiliations))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		login, email string
+		at           time.Time
+		want         string
+		ok           bool
+	}{
+		// domains and their subdomains
+		{"", "bob@example.com", day(2026, 1, 1), "Example Inc", true},
+		{"", "bob@Build.Example.COM", day(2026, 1, 1), "Example Inc", true},
+		{"", "bob@eu.example.com", day(2026, 1, 1), "Example Europe", true},
+		{"", "bob@fr.eu.example.com", day(2026, 1, 1), "Example Europe", true},
+		{"", "bob@example.net", day(2026, 1, 1), "", false},
+		{"", "bob", day(2026, 1, 1), "", false},
+		// dated lines apply before their date, the undated one after
+		{"", "jane@example.org", day(2018, 6, 1), "Startup", true},
+		{"", "jane@example.org", day(2019, 1, 1), "Example Inc", true},
+		{"", "jane@example.org", day(2021, 5, 31), "Example Inc", true},
+		{"", "jane@example.org", day(2021, 6, 1), "Acme Corp", true},
+		// logins win over emails
+		{"jdoe", "bob@example.com", day(2026, 1, 1), "Acme Corp", true},
+		{"JDOE", "", day(2026, 1, 1), "Acme Corp", true},
+		// a login whose only line has ended falls back to the email
+		{"old", "bob@example.com", day(2019, 1, 1), "Old Co", true},
+		{"old", "bob@example.com", day(2020, 1, 1), "Example Inc", true},
+		{"old", "", day(2020, 1, 1), "", false},
+	}
+	for _, tt := range tests {
+		got, ok := a.Company(tt.login, tt.email, tt.at)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Company(%q, %q, %s) = %q, %v, want %q, %v", tt.login, tt.email, tt.at.Format("2006-01-02"), got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestAffiliationsNil(t *testing.T) {
+	var a *Affiliations
+	if c, ok := a.Company("jdoe", "jane@example.com", day(2026, 1, 1)); ok {
+		t.Errorf("nil Company = %q, want no match", c)
+	}
+}
+
+func TestParseAffiliationsErrors(t *testing.T) {
+	for _, in := range []string{
+		"example.com",
+		"example.com Example Inc < June 2021",
+		"example.com Example Inc <",
+	} {
+		if _, err := ParseAffiliations(strings.NewReader(in)); err == nil {
+			t.Errorf("ParseAffiliations(%q) succeeded, want an error", in)
+		}
+	}
+}
@@ -9,6 +9,16 @@ import (
 	"github.com/google/go-github/github"
 )
 
+// reportErr logs err from a GitHub request, exiting the program unless the
+// error is only a rate limit.
+func reportErr(err error) {
+	if _, ok := err.(*github.RateLimitError); ok {
+		log.Println("hit rate limit")
+		return
+	}
+	log.Fatal(err)
+}
+
 // RateLimit prints to stdout number of api request remaining
 func RateLimit(client *github.Client) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
@@ -0,0 +1,127 @@
+package scrape
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// BadgeMetrics lists the metrics NewBadge knows how to compute.
+var BadgeMetrics = []string{"contributors", "commits", "commits-30d", "openprs", "closedprs"}
+
+// badgeColors are the named colors understood by shields.io.
+var badgeColors = map[string]string{
+	"brightgreen": "#4c1",
+	"green":       "#97ca00",
+	"yellowgreen": "#a4a61d",
+	"yellow":      "#dfb317",
+	"orange":      "#fe7d37",
+	"red":         "#e05d44",
+	"blue":        "#007ec6",
+	"lightgrey":   "#9f9f9f",
+	"grey":        "#555",
+}
+
+// Badge is a shields.io style badge.
+type Badge struct {
+	Label   string
+	Message string
+	Color   string
+}
+
+// NewBadge computes metric for an organization's repository and returns it
+// as a badge.
+func NewBadge(client *github.Client, org, repo, metric string, opts Options) (Badge, error) {
+	b := Badge{Color: "blue"}
+	switch metric {
+	case "contributors", "commits":
+		s, err := commitStats(client, org, repo, opts)
+		if err != nil {
+			return b, err
+		}
+		s, _ = opts.filterBots(s)
+		b.Label, b.Message = metric, strconv.Itoa(s.total())
+		if metric == "contributors" {
+			b.Message = strconv.Itoa(len(s))
+		}
+	case "commits-30d":
+		opts.Since = time.Now().AddDate(0, 0, -30)
+		s, err := commitStats(client, org, repo, opts)
+		if err != nil {
+			return b, err
+		}
+		s, _ = opts.filterBots(s)
+		b.Label, b.Message = "commits last 30d", strconv.Itoa(s.total())
+	case "openprs", "closedprs":
+		state := strings.TrimSuffix(metric, "prs")
+		s, err := prStats(client, org, repo, state, opts)
+		if err != nil {
+			return b, err
+		}
+		s, _ = opts.filterBots(s)
+		b.Label, b.Message = state+" PRs", strconv.Itoa(s.total())
+	default:
+		return b, fmt.Errorf("unknown badge metric %q, want one of %s", metric, strings.Join(BadgeMetrics, ", "))
+	}
+	return b, nil
+}
+
+// Endpoint returns the badge in the shields.io endpoint JSON schema.
+func (b Badge) Endpoint() ([]byte, error) {
+	return json.MarshalIndent(struct {
+		SchemaVersion int    `json:"schemaVersion"`
+		Label         string `json:"label"`
+		Message       string `json:"message"`
+		Color         string `json:"color"`
+	}{1, b.Label, b.Message, b.Color}, "", "  ")
+}
+
+// SVG renders the badge in the shields.io flat style.
+func (b Badge) SVG() string {
+	color := b.Color
+	if c, ok := badgeColors[color]; ok {
+		color = c
+	} else if !strings.HasPrefix(color, "#") {
+		color = "#" + color
+	}
+	lw := textWidth(b.Label) + 10
+	mw := textWidth(b.Message) + 10
+	w := lw + mw
+	label, msg := html.EscapeString(b.Label), html.EscapeString(b.Message)
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[4]s: %[5]s">
+<title>%[4]s: %[5]s</title>
+<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>
+<clipPath id="r"><rect width="%[1]d" height="20" rx="3" fill="#fff"/></clipPath>
+<g clip-path="url(#r)"><rect width="%[2]d" height="20" fill="#555"/><rect x="%[2]d" width="%[3]d" height="20" fill="%[6]s"/><rect width="%[1]d" height="20" fill="url(#s)"/></g>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="%.1[7]f" y="15" fill="#010101" fill-opacity=".3">%[4]s</text><text x="%.1[7]f" y="14">%[4]s</text>
+<text x="%.1[8]f" y="15" fill="#010101" fill-opacity=".3">%[5]s</text><text x="%.1[8]f" y="14">%[5]s</text>
+</g>
+</svg>
+`, w, lw, mw, label, msg, html.EscapeString(color), float64(lw)/2, float64(lw)+float64(mw)/2)
+}
+
+// textWidth approximates the width in pixels of s in 11px Verdana.
+func textWidth(s string) int {
+	w := 0.0
+	for _, r := range s {
+		switch {
+		case strings.ContainsRune("iljI.,:;'|!", r):
+			w += 3.5
+		case strings.ContainsRune(" frt()[]-/", r):
+			w += 4.5
+		case strings.ContainsRune("mwMW%", r):
+			w += 10.5
+		case r >= 'A' && r <= 'Z':
+			w += 7.5
+		default:
+			w += 6.5
+		}
+	}
+	return int(w + 0.5)
+}
@@ -0,0 +1,72 @@
+package scrape
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultBotPatterns match the logins of common bots that do not mark
+// themselves with a [bot] suffix or the Bot account type. They match whole
+// logins, so that people named after a bot are not taken for one.
+var DefaultBotPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)^dependabot(-preview)?$`),
+	regexp.MustCompile(`(?i)^renovate(-bot)?$`),
+	regexp.MustCompile(`(?i)^greenkeeper(io)?(-bot)?$`),
+	regexp.MustCompile(`(?i)^github-actions$`),
+	regexp.MustCompile(`(?i)^codecov(-io|-commenter)?$`),
+	regexp.MustCompile(`(?i)^snyk-bot$`),
+	regexp.MustCompile(`(?i)[-_]bot$`),
+	regexp.MustCompile(`(?i)-ci-robot$`),
+}
+
+// isBot reports whether the account login, of the given GitHub account
+// type, belongs to a bot.
+func (o Options) isBot(login, typ string) bool {
+	if typ == "Bot" || strings.HasSuffix(login, "[bot]") {
+		return true
+	}
+	patterns := o.BotPatterns
+	if patterns == nil {
+		patterns = DefaultBotPatterns
+	}
+	for _, re := range patterns {
+		if re.MatchString(login) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterBots drops bots from b, or everyone else with opts.OnlyBots, and
+// re-ranks the rest. The bots found in b are returned as well.
+func (o Options) filterBots(b byCount) (kept, bots byCount) {
+	for _, s := range b {
+		if s.Bot {
+			bots = append(bots, s)
+		}
+	}
+	if !o.ExcludeBots && !o.OnlyBots {
+		return b, bots
+	}
+	m := make(map[string]*stat)
+	for n, s := range b {
+		if s.Bot == o.OnlyBots {
+			s := s
+			m[fmt.Sprint(n)] = &s
+		}
+	}
+	return ranked(m), bots
+}
+
+// printBots prints the summary line of the bots found.
+func (o Options) printBots(bots byCount, unit string) {
+	if len(bots) == 0 {
+		return
+	}
+	note := ""
+	if o.ExcludeBots {
+		note = ", excluded"
+	}
+	fmt.Printf("TOTAL BOTS: %d (%d %s%s)\n", len(bots), bots.total(), unit, note)
+}
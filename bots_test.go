@@ -0,0 +1,85 @@
+package scrape
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestIsBot(t *testing.T) {
+	tests := []struct {
+		login, typ string
+		want       bool
+	}{
+		{"dependabot[bot]", "Bot", true},
+		{"some-app[bot]", "", true},
+		{"someapp", "Bot", true},
+		{"dependabot", "", true},
+		{"Dependabot-Preview", "", true},
+		{"renovate", "", true},
+		{"renovate-bot", "", true},
+		{"greenkeeperio-bot", "", true},
+		{"github-actions", "", true},
+		{"codecov-io", "", true},
+		{"snyk-bot", "", true},
+		{"release_bot", "", true},
+		{"k8s-ci-robot", "", true},
+		{"jdoe", "User", false},
+		{"renovate-user", "", false},
+		{"dependabot-fan", "", false},
+		{"github-actions-expert", "", false},
+		{"codecovery", "", false},
+		{"bot-builder", "", false},
+		{"robotics", "", false},
+		{"abbot", "", false},
+	}
+	for _, tt := range tests {
+		if got := (Options{}).isBot(tt.login, tt.typ); got != tt.want {
+			t.Errorf("isBot(%q, %q) = %v, want %v", tt.login, tt.typ, got, tt.want)
+		}
+	}
+
+	o := Options{BotPatterns: []*regexp.Regexp{regexp.MustCompile(`^ci-`)}}
+	if !o.isBot("ci-runner", "") || o.isBot("dependabot", "") {
+		t.Error("BotPatterns should replace DefaultBotPatterns")
+	}
+}
+
+func TestFilterBots(t *testing.T) {
+	b := byCount{
+		{Login: "jdoe", Count: 1},
+		{Login: "dependabot", Count: 5, Bot: true},
+		{Login: "ann", Count: 3},
+	}
+	logins := func(b byCount) []string {
+		var l []string
+		for _, s := range b {
+			l = append(l, s.Login)
+		}
+		return l
+	}
+	tests := []struct {
+		opts Options
+		want []string
+	}{
+		{Options{}, []string{"jdoe", "dependabot", "ann"}},
+		{Options{ExcludeBots: true}, []string{"jdoe", "ann"}},
+		{Options{OnlyBots: true}, []string{"dependabot"}},
+	}
+	for _, tt := range tests {
+		kept, bots := tt.opts.filterBots(b)
+		if got := logins(kept); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("filterBots with %+v kept %q, want %q", tt.opts, got, tt.want)
+		}
+		if got := logins(bots); !reflect.DeepEqual(got, []string{"dependabot"}) {
+			t.Errorf("filterBots with %+v found bots %q, want [dependabot]", tt.opts, got)
+		}
+	}
+
+	kept, _ := Options{ExcludeBots: true}.filterBots(b)
+	for n, s := range kept {
+		if want := len(kept) - n; s.Rank != want {
+			t.Errorf("%s ranked %d, want %d", s.Login, s.Rank, want)
+		}
+	}
+}
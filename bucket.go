@@ -0,0 +1,132 @@
+package scrape
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Buckets the activity of contributors can be split into, see
+// Options.Bucket.
+const (
+	BucketWeek    = "week"
+	BucketMonth   = "month"
+	BucketQuarter = "quarter"
+)
+
+// bucketStart returns the start of the bucket t falls in. Weeks start on
+// Monday.
+func bucketStart(t time.Time, bucket string) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch bucket {
+	case BucketWeek:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case BucketQuarter:
+		return quarterOf(day)
+	}
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+// nextBucket returns the start of the bucket after the one starting at t.
+func nextBucket(t time.Time, bucket string) time.Time {
+	switch bucket {
+	case BucketWeek:
+		return t.AddDate(0, 0, 7)
+	case BucketQuarter:
+		return t.AddDate(0, 3, 0)
+	}
+	return t.AddDate(0, 1, 0)
+}
+
+// bucketLabel names the bucket starting at t: 2026-W42, 2026-07 or
+// 2026-Q3.
+func bucketLabel(t time.Time, bucket string) string {
+	switch bucket {
+	case BucketWeek:
+		y, w := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", y, w)
+	case BucketQuarter:
+		return fmt.Sprintf("%d-Q%d", t.Year(), (int(t.Month())+2)/3)
+	}
+	return t.Format("2006-01")
+}
+
+// printBuckets prints to stdout the activity of every contributor, or
+// group, in b per opts.Bucket, with the total of every row and column.
+// The buckets run from opts.Since, or the first contribution, to
+// opts.Until, or the last one, empty buckets included.
+func printBuckets(b byCount, unit string, opts Options) {
+	var first, last time.Time
+	for _, s := range b {
+		for _, d := range s.Dates {
+			if d.IsZero() {
+				continue
+			}
+			if first.IsZero() || d.Before(first) {
+				first = d
+			}
+			if d.After(last) {
+				last = d
+			}
+		}
+	}
+	if !opts.Since.IsZero() {
+		first = opts.Since
+	}
+	if !opts.Until.IsZero() {
+		last = opts.Until.Add(-time.Nanosecond)
+	}
+	var buckets []time.Time
+	if !first.IsZero() {
+		for t := bucketStart(first, opts.Bucket); !t.After(last); t = nextBucket(t, opts.Bucket) {
+			buckets = append(buckets, t)
+		}
+	}
+	index := make(map[string]int)
+	for n, t := range buckets {
+		index[bucketLabel(t, opts.Bucket)] = n
+	}
+
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 10, 8, 0, '\t', 0)
+	group := "login"
+	if opts.By != "" {
+		group = opts.By
+	}
+	header := "rank\t" + group
+	for _, t := range buckets {
+		header += "\t" + bucketLabel(t, opts.Bucket)
+	}
+	fmt.Fprintln(w, header+"\ttotal")
+
+	totals := make([]float64, len(buckets))
+	total := 0.0
+	for _, s := range b {
+		counts := make([]float64, len(buckets))
+		n := 0.0
+		for j, d := range s.Dates {
+			if i, ok := index[bucketLabel(bucketStart(d.In(first.Location()), opts.Bucket), opts.Bucket)]; ok && !d.IsZero() {
+				counts[i] += s.weight(j)
+				totals[i] += s.weight(j)
+				n += s.weight(j)
+			}
+		}
+		total += n
+		row := fmt.Sprintf("%d\t%s", s.Rank, s.who())
+		for _, c := range counts {
+			row += "\t" + formatCount(c)
+		}
+		fmt.Fprintf(w, "%s\t%s\n", row, formatCount(n))
+	}
+	row := "\tTOTAL"
+	for _, c := range totals {
+		row += "\t" + formatCount(c)
+	}
+	fmt.Fprintf(w, "%s\t%s\n", row, formatCount(total))
+	fmt.Fprintln(w)
+	w.Flush()
+	fmt.Printf("TOTAL %s: %s\n", strings.ToUpper(unit), formatCount(total))
+	fmt.Printf("TOTAL %sS: %d\n", strings.ToUpper(opts.Bucket), len(buckets))
+}
@@ -0,0 +1,204 @@
+package scrape
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// otherChanges is the section of PRs without a known label and of commits
+// that did not come in through a PR.
+const otherChanges = "Other Changes"
+
+// ChangelogSections maps PR labels to release notes sections. The sections
+// are printed in the order they first appear.
+var ChangelogSections = []struct {
+	Label, Section string
+}{
+	{"breaking", "Breaking Changes"},
+	{"breaking-change", "Breaking Changes"},
+	{"feature", "Features"},
+	{"enhancement", "Features"},
+	{"bug", "Bug Fixes"},
+	{"bugfix", "Bug Fixes"},
+	{"fix", "Bug Fixes"},
+	{"documentation", "Documentation"},
+	{"docs", "Documentation"},
+	{"dependencies", "Dependencies"},
+}
+
+var (
+	mergeCommitRE  = regexp.MustCompile(`^Merge pull request #(\d+)`)
+	squashCommitRE = regexp.MustCompile(`\(#(\d+)\)\s*$`)
+)
+
+// prNumber returns the number of the PR a commit message says it merges,
+// or 0.
+func prNumber(msg string) int {
+	line := firstLine(msg)
+	for _, re := range []*regexp.Regexp{mergeCommitRE, squashCommitRE} {
+		if m := re.FindStringSubmatch(line); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			return n
+		}
+	}
+	return 0
+}
+
+// section returns the release notes section for a PR with the given labels.
+func section(labels []github.Label) string {
+	for _, s := range ChangelogSections {
+		for _, l := range labels {
+			if strings.EqualFold(l.GetName(), s.Label) {
+				return s.Section
+			}
+		}
+	}
+	return otherChanges
+}
+
+// Changelog prints to stdout markdown release notes for the changes to an
+// organization's repository between the refs base and head. Merged PRs are
+// grouped by label and followed by the list of contributors, first time
+// contributors highlighted. Bots are left out of the contributors with
+// opts.ExcludeBots, or are the only ones listed with opts.OnlyBots.
+func Changelog(client *github.Client, org, repo, base, head string, opts Options) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cmp, _, err := client.Repositories.CompareCommits(ctx, org, repo, base, head)
+	if err != nil {
+		return err
+	}
+	if n := cmp.GetTotalCommits(); n > len(cmp.Commits) {
+		log.Printf("warning: GitHub only compared %d of the %d commits in %s..%s, the notes are incomplete", len(cmp.Commits), n, base, head)
+	}
+
+	var order []string
+	entries := make(map[string][]string)
+	add := func(sec, entry string) {
+		if _, ok := entries[sec]; !ok {
+			order = append(order, sec)
+		}
+		entries[sec] = append(entries[sec], entry)
+	}
+	var logins []string
+	seen := make(map[string]bool)
+	credit := func(u *github.User) {
+		login := u.GetLogin()
+		if login == "" || seen[login] {
+			return
+		}
+		if bot := opts.isBot(login, u.GetType()); opts.ExcludeBots && bot || opts.OnlyBots && !bot {
+			return
+		}
+		seen[login] = true
+		logins = append(logins, login)
+	}
+
+	prs := make(map[int]bool)
+	var direct []github.RepositoryCommit
+	for _, c := range cmp.Commits {
+		if c.Author != nil {
+			credit(c.Author)
+		}
+		n := prNumber(c.Commit.GetMessage())
+		if n == 0 {
+			direct = append(direct, c)
+			continue
+		}
+		if prs[n] {
+			continue
+		}
+		prs[n] = true
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		i, _, err := client.Issues.Get(ctx, org, repo, n)
+		if err != nil {
+			return err
+		}
+		login := ""
+		if i.User != nil {
+			login = i.User.GetLogin()
+			credit(i.User)
+		}
+		add(section(i.Labels), fmt.Sprintf("- %s (#%d) @%s", i.GetTitle(), n, login))
+	}
+	for _, c := range direct {
+		if len(c.Parents) > 1 {
+			continue
+		}
+		sha := c.GetSHA()
+		if len(sha) > 7 {
+			sha = sha[:7]
+		}
+		add(otherChanges, fmt.Sprintf("- %s (%s)", firstLine(c.Commit.GetMessage()), sha))
+	}
+
+	var until time.Time
+	if cmp.BaseCommit != nil && cmp.BaseCommit.Commit != nil && cmp.BaseCommit.Commit.Committer != nil {
+		until = cmp.BaseCommit.Commit.Committer.GetDate()
+	}
+	first := make(map[string]bool)
+	for _, l := range logins {
+		if first[l], err = firstContribution(client, org, repo, l, until); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("# %s\n", head)
+	// keep the sections in the order of ChangelogSections
+	rank := make(map[string]int)
+	for i, s := range ChangelogSections {
+		if _, ok := rank[s.Section]; !ok {
+			rank[s.Section] = i
+		}
+	}
+	rank[otherChanges] = len(ChangelogSections)
+	sort.SliceStable(order, func(i, j int) bool { return rank[order[i]] < rank[order[j]] })
+	for _, sec := range order {
+		fmt.Printf("\n## %s\n\n", sec)
+		for _, e := range entries[sec] {
+			fmt.Println(e)
+		}
+	}
+
+	fmt.Printf("\n## Contributors to this release\n\n")
+	sort.Strings(logins)
+	for _, l := range logins {
+		if first[l] {
+			fmt.Printf("- **@%s** (first contribution!)\n", l)
+			continue
+		}
+		fmt.Printf("- @%s\n", l)
+	}
+	return nil
+}
+
+// firstContribution reports whether login had no commits in the repository
+// before until.
+func firstContribution(client *github.Client, org, repo, login string, until time.Time) (bool, error) {
+	if until.IsZero() {
+		return false, nil
+	}
+	opt := &github.CommitsListOptions{
+		Author: login,
+		Until:  until,
+		ListOptions: github.ListOptions{
+			PerPage: 1,
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	commits, _, err := client.Repositories.ListCommits(ctx, org, repo, opt)
+	if err != nil {
+		return false, err
+	}
+	return len(commits) == 0, nil
+}
@@ -0,0 +1,142 @@
+package scrape
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	sparkWidth = 26
+	barWidth   = 20
+	week       = 7 * 24 * time.Hour
+)
+
+var (
+	sparkTicks      = []rune("▁▂▃▄▅▆▇█")
+	asciiSparkTicks = []rune(" .:-=+*#")
+	barEighths      = []rune(" ▏▎▍▌▋▊▉")
+)
+
+// isTerminal reports whether f is attached to a terminal.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// resample folds vals into at most width buckets by summing neighbours.
+func resample(vals []int, width int) []int {
+	if len(vals) <= width {
+		return vals
+	}
+	out := make([]int, width)
+	for i, v := range vals {
+		out[i*width/len(vals)] += v
+	}
+	return out
+}
+
+// weekly counts dates into one bucket per week between start and end.
+func weekly(dates []time.Time, start, end time.Time) []int {
+	if !end.After(start) {
+		return nil
+	}
+	out := make([]int, int(end.Sub(start)/week)+1)
+	for _, d := range dates {
+		if d.Before(start) || d.After(end) {
+			continue
+		}
+		out[int(d.Sub(start)/week)]++
+	}
+	return out
+}
+
+// sparkline draws vals as a single line of block characters, falling back
+// to plain ascii when unicode is not wanted.
+func sparkline(vals []int, unicode bool) string {
+	ticks := asciiSparkTicks
+	if unicode {
+		ticks = sparkTicks
+	}
+	vals = resample(vals, sparkWidth)
+	max := 0
+	for _, v := range vals {
+		if v > max {
+			max = v
+		}
+	}
+	s := make([]rune, len(vals))
+	for i, v := range vals {
+		if max == 0 {
+			s[i] = ticks[0]
+			continue
+		}
+		s[i] = ticks[v*(len(ticks)-1)/max]
+	}
+	return string(s)
+}
+
+// bar draws a horizontal bar for n scaled so that max fills barWidth.
+func bar(n, max float64, unicode bool) string {
+	if max <= 0 || n <= 0 {
+		return ""
+	}
+	if !unicode {
+		return strings.Repeat("#", int(n*barWidth/max))
+	}
+	eighths := int(n * barWidth * 8 / max)
+	s := strings.Repeat("█", eighths/8)
+	if r := eighths % 8; r > 0 {
+		s += string(barEighths[r])
+	}
+	return s
+}
+
+// charts renders the extra columns added by Options.Charts.
+type charts struct {
+	unicode    bool
+	max        float64
+	start, end time.Time
+}
+
+// newCharts prepares the chart columns for b.
+func newCharts(b byCount) *charts {
+	c := &charts{unicode: isTerminal(os.Stdout)}
+	for _, s := range b {
+		if s.score() > c.max {
+			c.max = s.score()
+		}
+		for _, d := range s.Dates {
+			if d.IsZero() {
+				continue
+			}
+			if c.start.IsZero() || d.Before(c.start) {
+				c.start = d
+			}
+			if d.After(c.end) {
+				c.end = d
+			}
+		}
+	}
+	return c
+}
+
+// header returns the column titles to append to a table header.
+func (c *charts) header() string {
+	return "\tactivity\t"
+}
+
+// columns returns the sparkline and bar columns for s, its bar showing
+// its score.
+func (c *charts) columns(s stat) string {
+	return c.series(weekly(s.Dates, c.start, c.end), s.score())
+}
+
+// series returns the sparkline and bar columns for a precomputed weekly
+// series and total.
+func (c *charts) series(weeks []int, total float64) string {
+	return "\t" + sparkline(weeks, c.unicode) + "\t" + bar(total, c.max, c.unicode)
+}
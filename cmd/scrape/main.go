@@ -3,9 +3,12 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
+	"regexp"
 	"strings"
+	"time"
 
 	"golang.org/x/oauth2"
 
@@ -16,6 +19,7 @@ import (
 
 type config struct {
 	Token string
+	Salt  string
 }
 
 var apiRates = flag.NewFlagSet("apirates", flag.ExitOnError)
@@ -23,41 +27,241 @@ var allCommits = flag.NewFlagSet("commits", flag.ExitOnError)
 var openPRs = flag.NewFlagSet("openprs", flag.ExitOnError)
 var closedPRs = flag.NewFlagSet("closedprs", flag.ExitOnError)
 var top = flag.NewFlagSet("top100", flag.ExitOnError)
+var tui = flag.NewFlagSet("tui", flag.ExitOnError)
+var badge = flag.NewFlagSet("badge", flag.ExitOnError)
+var contributorsFile = flag.NewFlagSet("contributors-file", flag.ExitOnError)
+var changelog = flag.NewFlagSet("changelog", flag.ExitOnError)
+var domains = flag.NewFlagSet("domains", flag.ExitOnError)
+var committers = flag.NewFlagSet("committers", flag.ExitOnError)
+var userReport = flag.NewFlagSet("user", flag.ExitOnError)
+var reviews = flag.NewFlagSet("reviews", flag.ExitOnError)
+var forks = flag.NewFlagSet("forks", flag.ExitOnError)
+var dirs = flag.NewFlagSet("dirs", flag.ExitOnError)
+var compare = flag.NewFlagSet("compare", flag.ExitOnError)
+
+var opts scrape.Options
+var mailmap string
+var affiliations string
+var botPatterns stringList
+var trailers stringList
+var repoFilter scrape.RepoFilter
+var repoTopics stringList
+var repoMatch string
+var reposFile string
+var repoSearch string
+var teamOrg string
+var paths stringList
+
+// stringList is a flag that may be given several times.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+// periodFlag is a flag holding the start of a date or period, or its end
+// with end set. See scrape.ParsePeriod for the formats.
+type periodFlag struct {
+	t   *time.Time
+	end bool
+}
+
+func (f periodFlag) String() string {
+	if f.t == nil || f.t.IsZero() {
+		return ""
+	}
+	return f.t.Format("2006-01-02")
+}
+
+func (f periodFlag) Set(s string) error {
+	p, err := scrape.ParsePeriod(s, time.Now())
+	if err != nil {
+		return err
+	}
+	*f.t = p.Start
+	if f.end {
+		*f.t = p.End
+	}
+	return nil
+}
+
+var (
+	badgeOut   = badge.String("o", "", "write the badge to this file instead of stdout")
+	badgeJSON  = badge.Bool("json", false, "write shields.io endpoint JSON instead of SVG")
+	badgeLabel = badge.String("label", "", "override the badge label")
+	badgeColor = badge.String("color", "", "badge color, a shields.io color name or hex value")
+
+	contributorsOut = contributorsFile.String("o", "CONTRIBUTORS.md", "contributors file to generate or update")
+	contributorsRC  = contributorsFile.String("rc", "", "also update this all-contributors config, e.g. .all-contributorsrc")
+
+	dirsDepth = dirs.Int("depth", 1, "number of path components directories are cut to")
+	dirsTop   = dirs.Int("top", 10, "number of contributors listed per directory, 0 for all")
+	dirsClone = dirs.String("clone", "", "read the files touched by commits from this local clone instead of the API")
+	dirsMax   = dirs.Int("max-requests", 1000, "refuse to fetch the files of more commits than this without -clone, 0 for no limit")
+
+	comparePeriod = compare.String("period", "", "period to report on, e.g. 2026-Q3, 2026-07 or last-quarter")
+	compareVs     = compare.String("vs", "", "period to compare with (default the period of the same length right before)")
+
+	forksAll = forks.Bool("all", false, "also list the forks without commits of their own")
+
+	userList = userReport.Bool("list", false, "list every contribution after the per repository counts")
+)
+
+func init() {
+	for _, fs := range []*flag.FlagSet{allCommits, openPRs, closedPRs, top, domains, committers, reviews} {
+		fs.BoolVar(&opts.Charts, "charts", false, "add activity sparklines and bars to each row")
+	}
+	for _, fs := range []*flag.FlagSet{allCommits, openPRs, closedPRs, reviews} {
+		fs.StringVar(&opts.By, "by", "", "roll contributors up per group, one of: company, team")
+		fs.StringVar(&affiliations, "affiliations", "", "gitdm style file mapping domains, emails and @logins to companies")
+		fs.BoolVar(&opts.ProfileCompanies, "profile-company", false, "use the GitHub profile company of contributors missing from -affiliations")
+		fs.StringVar(&teamOrg, "team-org", "", "org whose teams -by team uses (default the org of the repository)")
+		fs.StringVar(&opts.TeamPolicy, "team-policy", scrape.TeamSplit, "how -by team counts members of several teams, one of: split, duplicate, primary")
+	}
+	for _, fs := range []*flag.FlagSet{allCommits, openPRs, closedPRs, top, tui, badge, contributorsFile, changelog, domains, committers, reviews, dirs, compare} {
+		fs.BoolVar(&opts.ExcludeBots, "exclude-bots", false, "leave bots out of the results")
+		fs.BoolVar(&opts.OnlyBots, "only-bots", false, "only show bots")
+		fs.Var(&botPatterns, "bot-pattern", "regular expression matching bot logins, in addition to the defaults (repeatable)")
+	}
+	for _, fs := range []*flag.FlagSet{allCommits, openPRs, closedPRs} {
+		fs.BoolVar(&repoFilter.Archived, "archived", false, "with an org or glob, include archived repositories")
+		fs.BoolVar(&repoFilter.Forks, "forks", false, "with an org or glob, include forks")
+		fs.StringVar(&repoFilter.Visibility, "visibility", "all", "with an org or glob, only the repositories that are one of: all, public, private")
+		fs.Var(&repoTopics, "topic", "with an org or glob, only the repositories with this topic (repeatable)")
+		fs.StringVar(&repoMatch, "match", "", "with an org or glob, only the repositories whose name matches this regular expression")
+		fs.StringVar(&reposFile, "repos-file", "", "also aggregate the org/repo, org/glob or org patterns listed in this file, one per line")
+		fs.StringVar(&repoSearch, "search", "", "also aggregate the repositories matching this search query, e.g. \"topic:foo org:bar\"")
+	}
+	for _, fs := range []*flag.FlagSet{allCommits, committers, domains, dirs} {
+		fs.StringVar(&opts.Ref, "branch", "", "count the commits of this branch instead of the default branch")
+		fs.StringVar(&opts.Ref, "ref", "", "count the commits reachable from this branch, tag or SHA")
+		fs.Var(&paths, "path", "only count the commits touching this file or directory (repeatable)")
+		fs.StringVar(&opts.Author, "author", "", "only count the commits of this login or email")
+	}
+	for _, fs := range []*flag.FlagSet{allCommits, openPRs, closedPRs, top, tui, badge, contributorsFile, domains, committers, reviews, dirs, userReport} {
+		fs.Var(periodFlag{t: &opts.Since}, "since", "only count contributions from this date or the start of this period on, e.g. 2026-07-01, 90d, last-quarter")
+		fs.Var(periodFlag{t: &opts.Until, end: true}, "until", "only count contributions up to this date or the end of this period")
+	}
+	for _, fs := range []*flag.FlagSet{allCommits, openPRs, closedPRs, reviews} {
+		fs.StringVar(&opts.Bucket, "bucket", "", "show the activity of every contributor per period, one of: week, month, quarter")
+	}
+	for _, fs := range []*flag.FlagSet{openPRs, closedPRs, tui, contributorsFile} {
+		fs.StringVar(&opts.DateField, "date-field", scrape.DateCreated, "date of PRs -since and -until apply to, one of: created, merged, closed")
+	}
+	allCommits.BoolVar(&opts.CoAuthors, "coauthors", false, "credit the people named in Co-authored-by trailers")
+	allCommits.Var(&trailers, "trailer", "commit trailer crediting co-authors, e.g. Signed-off-by (repeatable, default Co-authored-by)")
+	allCommits.Float64Var(&opts.CoAuthorWeight, "coauthor-weight", 1, "credit given to a co-author for each commit")
+	for _, fs := range []*flag.FlagSet{allCommits, openPRs, closedPRs, top, tui, contributorsFile, committers, reviews} {
+		fs.BoolVar(&opts.Enrich, "enrich", false, "add the name, company, location, creation date and followers of every contributor")
+		fs.StringVar(&opts.CacheDir, "cache-dir", "", "directory to cache GitHub profiles in (default the user cache directory)")
+		fs.BoolVar(&opts.TrackLogins, "track-logins", false, "remember the logins of every account in the cache directory and show their earlier logins")
+	}
+	for _, fs := range []*flag.FlagSet{allCommits, tui, contributorsFile, committers, dirs, compare} {
+		fs.StringVar(&opts.Privacy, "privacy", "", "keep emails out of the output, one of: omit, mask, hash")
+	}
+	for _, fs := range []*flag.FlagSet{allCommits, tui, badge, contributorsFile, domains, committers, dirs, compare} {
+		fs.StringVar(&mailmap, "mailmap", "", "canonicalize commit authors with this .mailmap file, or \"repo\" for the repository's own")
+		fs.BoolVar(&opts.ResolveEmails, "resolve-emails", false, "look up the GitHub account of commit authors not linked to one")
+	}
+}
 
 func main() {
-	if len(os.Args) != 3 {
-		fmt.Println("usage: scrape <command> org/repo")
+	if len(os.Args) < 3 {
+		fmt.Println("usage: scrape <command> [options] org/repo")
+		fmt.Println("       scrape <command> [options] org/repo|org/glob|org ...  (commits, openprs and closedprs)")
 		fmt.Println("The scrape commands are: ")
 		fmt.Println(" top100     See top 100 commiters to project")
 		fmt.Println(" commits    See all user's commits to project")
 		fmt.Println(" apirates   See current used api requests/total")
 		fmt.Println(" openprs    See all open PRs to project")
 		fmt.Println(" closedprs  See all closed PRs to project")
+		fmt.Println(" reviews    See all reviewers of PRs to project")
+		fmt.Println(" tui        Browse all of the above interactively")
+		fmt.Println(" badge      Generate a README badge: scrape badge <metric> org/repo")
+		fmt.Println(" contributors-file  Generate or update CONTRIBUTORS.md")
+		fmt.Println(" changelog  Release notes between two refs: scrape changelog org/repo v1.0..v1.1")
+		fmt.Println(" domains    See commits per author email domain")
+		fmt.Println(" committers See who landed the commits to project, and whose")
+		fmt.Println(" dirs       See the top contributors to every directory of project")
+		fmt.Println(" compare    Compare a period with another: scrape compare -period 2026-Q3 -vs 2026-Q2 org/repo")
+		fmt.Println(" forks      See the forks of project carrying commits of their own")
+		fmt.Println(" user       See what a user contributed across repos: scrape user <login>")
+		fmt.Println("Run 'scrape <command> -h' to see the options of a command.")
 		return
 	}
 
+	var cmd *flag.FlagSet
 	switch os.Args[1] {
 	case "apirates":
-		apiRates.Parse(os.Args[2:])
+		cmd = apiRates
 	case "commits":
-		allCommits.Parse(os.Args[2:])
+		cmd = allCommits
 	case "openprs":
-		openPRs.Parse(os.Args[2:])
+		cmd = openPRs
 	case "closedprs":
-		closedPRs.Parse(os.Args[2:])
+		cmd = closedPRs
 	case "top100":
-		top.Parse(os.Args[2:])
+		cmd = top
+	case "tui":
+		cmd = tui
+	case "badge":
+		cmd = badge
+	case "contributors-file":
+		cmd = contributorsFile
+	case "changelog":
+		cmd = changelog
+	case "domains":
+		cmd = domains
+	case "committers":
+		cmd = committers
+	case "user":
+		cmd = userReport
+	case "reviews":
+		cmd = reviews
+	case "forks":
+		cmd = forks
+	case "dirs":
+		cmd = dirs
+	case "compare":
+		cmd = compare
 	default:
 		fmt.Printf("%q is not valid command.\n", os.Args[1])
 		os.Exit(2)
 	}
+	cmd.Parse(os.Args[2:])
 
-	ro := strings.Split(os.Args[2], "/")
-	if len(ro) != 2 {
+	args := cmd.Args()
+	var metric, refs string
+	if badge.Parsed() && len(args) > 0 {
+		metric, args = args[0], args[1:]
+	}
+	if changelog.Parsed() && len(args) > 0 {
+		refs, args = args[len(args)-1], args[:len(args)-1]
+	}
+	// commits, openprs and closedprs may aggregate a set of repositories
+	multi := allCommits.Parsed() || openPRs.Parsed() || closedPRs.Parsed()
+	var org, repo, login string
+	if userReport.Parsed() && len(args) == 1 {
+		login = args[0]
+	}
+	if len(args) == 1 && !userReport.Parsed() && reposFile == "" && repoSearch == "" {
+		ro := strings.Split(args[0], "/")
+		if len(ro) == 2 && !strings.ContainsAny(args[0], "*?[") {
+			org, repo = ro[0], ro[1]
+		}
+	}
+	switch {
+	case repo != "", login != "":
+	case multi && (len(args) > 0 || reposFile != "" || repoSearch != ""):
+	default:
 		fmt.Println("poorly formated org/repo")
 		return
 	}
-	org, repo := ro[0], ro[1]
 
 	config := &config{}
 	err := envconfig.Process("scrape", config)
@@ -69,6 +273,19 @@ func main() {
 		os.Exit(3)
 	}
 
+	opts.Salt = config.Salt
+	switch opts.Privacy {
+	case "", scrape.PrivacyOmit, scrape.PrivacyMask:
+	case scrape.PrivacyHash:
+		if opts.Salt == "" {
+			fmt.Println("-privacy hash requires SCRAPE_SALT env variable to be defined")
+			os.Exit(3)
+		}
+	default:
+		fmt.Printf("%q is not a valid -privacy mode.\n", opts.Privacy)
+		os.Exit(2)
+	}
+
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: config.Token},
 	)
@@ -80,20 +297,259 @@ func main() {
 		scrape.RateLimit(client)
 		return
 	}
+	if login != "" {
+		if opts.Since.IsZero() {
+			opts.Since = time.Now().AddDate(-1, 0, 0)
+		}
+		if err := scrape.UserReport(client, login, *userList, opts); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if multi && repo == "" {
+		getRepos(client, args)
+		return
+	}
 	if missingOrg(org) || missingRepo(repo) {
 		return
 	}
+	setup(client, org, repo)
 	if allCommits.Parsed() {
-		scrape.GetAllCommits(client, org, repo)
+		scrape.GetAllCommits(client, org, repo, opts)
+	}
+	if domains.Parsed() {
+		scrape.Domains(client, org, repo, opts)
+	}
+	if committers.Parsed() {
+		scrape.Committers(client, org, repo, opts)
 	}
 	if top.Parsed() {
-		scrape.Top100(client, org, repo)
+		scrape.Top100(client, org, repo, opts)
+	}
+	if openPRs.Parsed() {
+		scrape.GetPRs(client, org, repo, "open", opts)
+	}
+	if compare.Parsed() {
+		comparePeriods(client, org, repo)
+	}
+	if dirs.Parsed() {
+		if *dirsDepth < 1 {
+			fmt.Println("-depth must be at least 1")
+			os.Exit(2)
+		}
+		if err := scrape.Dirs(client, org, repo, *dirsClone, *dirsDepth, *dirsTop, *dirsMax, opts); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if forks.Parsed() {
+		scrape.Forks(client, org, repo, *forksAll)
+	}
+	if reviews.Parsed() {
+		scrape.GetReviews(client, org, repo, opts)
+	}
+	if closedPRs.Parsed() {
+		scrape.GetPRs(client, org, repo, "closed", opts)
+	}
+	if badge.Parsed() {
+		writeBadge(client, org, repo, metric)
+	}
+	if contributorsFile.Parsed() {
+		if err := scrape.UpdateContributorsFile(client, org, repo, *contributorsOut, *contributorsRC, opts); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if changelog.Parsed() {
+		r := strings.SplitN(refs, "..", 2)
+		if len(r) != 2 || r[0] == "" || r[1] == "" {
+			fmt.Println("poorly formated range, want base..head")
+			return
+		}
+		if err := scrape.Changelog(client, org, repo, r[0], r[1], opts); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if tui.Parsed() {
+		if err := scrape.Browse(client, org, repo, opts); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// setup loads the files and checks the options shared by the commands, for
+// the repository org/repo, or all of org when repo is empty.
+func setup(client *github.Client, org, repo string) {
+	var err error
+	switch mailmap {
+	case "":
+	case "repo":
+		if repo == "" {
+			fmt.Println("-mailmap repo requires a single org/repo")
+			os.Exit(2)
+		}
+		opts.Mailmap, err = scrape.FetchMailmap(client, org, repo)
+	default:
+		opts.Mailmap, err = scrape.LoadMailmap(mailmap)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	switch opts.By {
+	case "", "company":
+	case "team":
+		switch opts.TeamPolicy {
+		case scrape.TeamSplit, scrape.TeamDuplicate, scrape.TeamPrimary:
+		default:
+			fmt.Printf("%q is not a valid -team-policy.\n", opts.TeamPolicy)
+			os.Exit(2)
+		}
+		if teamOrg == "" {
+			teamOrg = org
+		}
+		if teamOrg == "" {
+			fmt.Println("-by team requires -team-org with several repositories")
+			os.Exit(2)
+		}
+		if opts.Teams, err = scrape.LoadTeams(client, teamOrg); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		fmt.Printf("%q is not a valid -by group.\n", opts.By)
+		os.Exit(2)
+	}
+	if opts.ExcludeBots && opts.OnlyBots {
+		fmt.Println("-exclude-bots and -only-bots are mutually exclusive")
+		os.Exit(2)
+	}
+	if len(botPatterns) > 0 {
+		opts.BotPatterns = append([]*regexp.Regexp{}, scrape.DefaultBotPatterns...)
+		for _, p := range botPatterns {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				log.Fatal(err)
+			}
+			opts.BotPatterns = append(opts.BotPatterns, re)
+		}
+	}
+	switch opts.Bucket {
+	case "", scrape.BucketWeek, scrape.BucketMonth, scrape.BucketQuarter:
+	default:
+		fmt.Printf("%q is not a valid -bucket.\n", opts.Bucket)
+		os.Exit(2)
+	}
+	switch opts.DateField {
+	case "", scrape.DateCreated, scrape.DateMerged, scrape.DateClosed:
+	default:
+		fmt.Printf("%q is not a valid -date-field.\n", opts.DateField)
+		os.Exit(2)
+	}
+	opts.Trailers = trailers
+	opts.Paths = paths
+	if affiliations != "" {
+		if opts.Affiliations, err = scrape.LoadAffiliations(affiliations); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// getRepos runs the commits, openprs or closedprs command against the
+// repositories named by patterns, -repos-file and -search.
+func getRepos(client *github.Client, patterns []string) {
+	setup(client, "", "")
+	switch repoFilter.Visibility {
+	case "all", "public", "private":
+	default:
+		fmt.Printf("%q is not a valid -visibility.\n", repoFilter.Visibility)
+		os.Exit(2)
+	}
+	repoFilter.Topics = repoTopics
+	if repoMatch != "" {
+		re, err := regexp.Compile(repoMatch)
+		if err != nil {
+			log.Fatal(err)
+		}
+		repoFilter.Match = re
+	}
+	if reposFile != "" {
+		listed, err := scrape.LoadRepoList(reposFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		patterns = append(patterns, listed...)
+	}
+	if repoSearch != "" {
+		found, err := scrape.SearchRepos(client, repoSearch)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, r := range found {
+			patterns = append(patterns, r.String())
+		}
+	}
+	repos, err := scrape.ResolveRepos(client, patterns, repoFilter)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(repos) == 0 {
+		fmt.Println("no repositories match")
+		return
+	}
+	if allCommits.Parsed() {
+		scrape.GetAllCommitsAcross(client, repos, opts)
 	}
 	if openPRs.Parsed() {
-		scrape.GetPRs(client, org, repo, "open")
+		scrape.GetPRsAcross(client, repos, "open", opts)
 	}
 	if closedPRs.Parsed() {
-		scrape.GetPRs(client, org, repo, "closed")
+		scrape.GetPRsAcross(client, repos, "closed", opts)
+	}
+}
+
+// comparePeriods runs the compare command on org/repo.
+func comparePeriods(client *github.Client, org, repo string) {
+	if *comparePeriod == "" {
+		fmt.Println("compare requires -period")
+		os.Exit(2)
+	}
+	now := time.Now()
+	period, err := scrape.ParsePeriod(*comparePeriod, now)
+	if err != nil {
+		log.Fatal(err)
+	}
+	vs := period.Previous()
+	if *compareVs != "" {
+		if vs, err = scrape.ParsePeriod(*compareVs, now); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if err := scrape.Compare(client, org, repo, period, vs, opts); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func writeBadge(client *github.Client, org, repo, metric string) {
+	b, err := scrape.NewBadge(client, org, repo, metric, opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *badgeLabel != "" {
+		b.Label = *badgeLabel
+	}
+	if *badgeColor != "" {
+		b.Color = *badgeColor
+	}
+	out := []byte(b.SVG())
+	if *badgeJSON {
+		if out, err = b.Endpoint(); err != nil {
+			log.Fatal(err)
+		}
+		out = append(out, '\n')
+	}
+	if *badgeOut == "" {
+		os.Stdout.Write(out)
+		return
+	}
+	if err := ioutil.WriteFile(*badgeOut, out, 0644); err != nil {
+		log.Fatal(err)
 	}
 }
 
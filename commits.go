@@ -6,87 +6,343 @@ import (
 	"log"
 	"os"
 	"sort"
+	"strings"
 	"text/tabwriter"
 	"time"
 
 	"github.com/google/go-github/github"
 )
 
-func checkAndAddEmail(e string, emails []string) bool {
-	for _, s := range emails {
-		if e == s {
+// contains reports whether list holds s.
+func contains(s string, list []string) bool {
+	for _, l := range list {
+		if l == s {
 			return true
 		}
 	}
 	return false
 }
 
-// GetAllCommits prints to stdout a sorted list of all commits to a
-// specified organization's repository
-func GetAllCommits(client *github.Client, org, repo string) {
-	opt := &github.CommitsListOptions{
-		ListOptions: github.ListOptions{
-			PerPage: 100,
-		},
+// firstLine returns the summary line of a commit message.
+func firstLine(msg string) string {
+	if i := strings.IndexByte(msg, '\n'); i >= 0 {
+		return msg[:i]
 	}
+	return msg
+}
 
-	m := make(map[string]*stat)
+// listCommits fetches every commit of a repository matching opt.
+func listCommits(client *github.Client, org, repo string, opt *github.CommitsListOptions) ([]*github.RepositoryCommit, error) {
+	var all []*github.RepositoryCommit
 	for {
 		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 		defer cancel()
 		commits, resp, err := client.Repositories.ListCommits(ctx, org, repo, opt)
-		if _, ok := err.(*github.RateLimitError); ok {
-			log.Println("hit rate limit")
-			return
+		if err != nil {
+			return nil, err
 		}
+		all = append(all, commits...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.ListOptions.Page = resp.NextPage
+	}
+	return all, nil
+}
+
+// repoCommits fetches the commits of a repository in the scope of opts:
+// those reachable from opts.Ref, touching any of opts.Paths and written by
+// opts.Author. Commits are listed newest first.
+func repoCommits(client *github.Client, org, repo string, opts Options) ([]*github.RepositoryCommit, error) {
+	paths := opts.Paths
+	if len(paths) == 0 {
+		paths = []string{""}
+	}
+	var all []*github.RepositoryCommit
+	seen := make(map[string]bool)
+	for _, p := range paths {
+		opt := &github.CommitsListOptions{
+			SHA:    opts.Ref,
+			Path:   p,
+			Author: opts.Author,
+			Since:  opts.Since,
+			Until:  opts.Until,
+			ListOptions: github.ListOptions{
+				PerPage: 100,
+			},
+		}
+		commits, err := listCommits(client, org, repo, opt)
 		if err != nil {
-			log.Fatal(err)
+			return nil, err
 		}
 		for _, c := range commits {
-			a := "username missing"
-			e := "fake@fake.com"
-			if c.Author != nil {
-				a = *c.Author.Login
+			if !seen[c.GetSHA()] {
+				seen[c.GetSHA()] = true
+				all = append(all, c)
+			}
+		}
+	}
+	if len(paths) > 1 {
+		sort.SliceStable(all, func(i, j int) bool {
+			return all[i].Commit.Committer.GetDate().After(all[j].Commit.Committer.GetDate())
+		})
+	}
+	return all, nil
+}
+
+// commitStats fetches the commits of a repository in the scope of opts,
+// see repoCommits, and returns them aggregated per author, ranked and
+// sorted by ascending count. Authors are identified by their GitHub user
+// ID, so renamed accounts are counted once under their current login.
+// Author emails are canonicalized with opts.Mailmap first, and commits
+// whose canonical email belongs to an account are credited to that
+// account. The remaining commits without a GitHub account are grouped by
+// their git author email. With opts.CoAuthors the people named in the
+// trailers of a commit are credited as well.
+func commitStats(client *github.Client, org, repo string, opts Options) (byCount, error) {
+	commits, err := repoCommits(client, org, repo, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(commits))
+	emails := make([]string, len(commits))
+	counts := make(map[string]map[string]int)
+	// commits are listed newest first, so the first account seen for a
+	// key carries its current login
+	accounts := make(map[string]*github.User)
+	for n, c := range commits {
+		e := "fake@fake.com"
+		if c.Commit.Author != nil {
+			names[n], e = opts.Mailmap.Lookup(c.Commit.Author.GetName(), c.Commit.Author.GetEmail())
+		}
+		emails[n] = e
+		if c.Author == nil {
+			continue
+		}
+		k := accountKey(c.Author)
+		if counts[e] == nil {
+			counts[e] = make(map[string]int)
+		}
+		counts[e][k]++
+		if _, ok := accounts[k]; !ok {
+			accounts[k] = c.Author
+		}
+	}
+	// every canonical email belongs to the account that used it the most
+	owner := make(map[string]string)
+	for e, keys := range counts {
+		best := 0
+		for k, n := range keys {
+			if n > best || (n == best && k < owner[e]) {
+				owner[e], best = k, n
 			}
-			if c.Commit.Author != nil {
-				e = *c.Commit.Author.Email
+		}
+	}
+	if opts.ResolveEmails {
+		var unowned []string
+		for n, c := range commits {
+			if _, ok := owner[emails[n]]; !ok && c.Author == nil && c.Commit.Author != nil {
+				unowned = append(unowned, emails[n])
+				owner[emails[n]] = ""
 			}
-			_, ok := m[a]
-			if !ok {
-				m[a] = &stat{Login: a, Email: []string{e}, Count: 1}
+		}
+		if len(unowned) > 1 {
+			log.Printf("resolving %d emails, which takes about %v", len(unowned), time.Duration(len(unowned)-1)*searchInterval)
+		}
+		found, err := resolveEmails(client, unowned)
+		switch err.(type) {
+		case nil:
+		case *github.RateLimitError, *github.AbuseRateLimitError:
+			log.Printf("hit search rate limit, keeping the %d accounts found", len(found))
+		default:
+			return nil, err
+		}
+		for e, u := range found {
+			k := accountKey(u)
+			owner[e] = k
+			if _, ok := accounts[k]; !ok {
+				accounts[k] = u
+			}
+		}
+	}
+
+	// identify returns the map key, display name and whether a commit
+	// identity has no GitHub account.
+	identify := func(key, name, e string) (string, string, bool) {
+		if k := owner[e]; k != "" {
+			key = k
+		}
+		if u := noreplyUser(e); key == "" && u != nil {
+			key = accountKey(u)
+			if u.GetID() == 0 {
+				for k, a := range accounts {
+					if strings.EqualFold(a.GetLogin(), u.GetLogin()) {
+						key = k
+						break
+					}
+				}
+			}
+			if _, ok := accounts[key]; !ok {
+				accounts[key] = u
+			}
+		}
+		if key != "" {
+			return key, accounts[key].GetLogin(), false
+		}
+		return "email:" + strings.ToLower(e), opts.gitName(name, e), true
+	}
+	// newStat returns an empty stat for the identity under key.
+	newStat := func(key, a string, unlinked bool) *stat {
+		if unlinked || accounts[key] == nil {
+			return &stat{Login: a, Unlinked: unlinked, Bot: opts.isBot(a, ""), Email: []string{}}
+		}
+		return opts.accountStat(accounts[key])
+	}
+
+	m := make(map[string]*stat)
+	for n, c := range commits {
+		k, a, unlinked := "username missing", "username missing", false
+		e := emails[n]
+		if c.Author != nil {
+			k, a, unlinked = identify(accountKey(c.Author), names[n], e)
+		} else if c.Commit.Author != nil {
+			k, a, unlinked = identify("", names[n], e)
+		}
+		var d time.Time
+		if c.Commit.Author != nil {
+			d = c.Commit.Author.GetDate()
+		}
+		it := item{Date: d, Ref: c.GetSHA(), SHA: c.GetSHA(), Title: firstLine(c.Commit.GetMessage()), Email: e}
+		if len(it.Ref) > 7 {
+			it.Ref = it.Ref[:7]
+		}
+		tmp, ok := m[k]
+		if !ok {
+			tmp = newStat(k, a, unlinked)
+			m[k] = tmp
+		}
+		tmp.Count += 1
+		tmp.Dates = append(tmp.Dates, d)
+		tmp.Items = append(tmp.Items, it)
+		if !contains(e, tmp.Email) {
+			tmp.Email = append(tmp.Email, e)
+		}
+
+		if !opts.CoAuthors {
+			continue
+		}
+		credited := map[string]bool{k: true}
+		for _, t := range parseTrailers(c.Commit.GetMessage(), opts.trailers()) {
+			name, e := opts.Mailmap.Lookup(t.name, t.email)
+			k, a, unlinked := identify("", name, e)
+			if credited[k] {
 				continue
 			}
-			tmp := m[a]
-			tmp.Count += 1
-			if !checkAndAddEmail(e, tmp.Email) {
+			credited[k] = true
+			tmp, ok := m[k]
+			if !ok {
+				tmp = newStat(k, a, unlinked)
+				m[k] = tmp
+			}
+			tmp.CoAuthored++
+			tmp.Credit += opts.CoAuthorWeight
+			if !contains(e, tmp.Email) {
 				tmp.Email = append(tmp.Email, e)
 			}
 		}
-		if resp.NextPage == 0 {
-			break
+	}
+
+	b := ranked(m)
+	opts.trackLogins(b)
+	return b, nil
+}
+
+// GetAllCommits prints to stdout a sorted list of all commits to a
+// specified organization's repository
+func GetAllCommits(client *github.Client, org, repo string, opts Options) {
+	b, err := commitStats(client, org, repo, opts)
+	if err != nil {
+		reportErr(err)
+		return
+	}
+	printCommits(client, b, opts)
+}
+
+// printCommits prints the commit leaderboard b to stdout.
+func printCommits(client *github.Client, b byCount, opts Options) {
+	b, bots := opts.filterBots(b)
+	if opts.By != "" {
+		g, err := byGroup(client, b, opts)
+		if err != nil {
+			reportErr(err)
+			return
 		}
-		opt.ListOptions.Page = resp.NextPage
+		if opts.Bucket != "" {
+			printBuckets(g, "commits", opts)
+			return
+		}
+		printGroups(g, "commits", opts)
+		return
+	}
+	if opts.Bucket != "" {
+		printBuckets(b, "commits", opts)
+		return
 	}
 
-	b := byCount{}
-	for _, v := range m {
-		b = append(b, *v)
+	b = opts.redact(b)
+	b, err := enrich(client, b, opts)
+	if err != nil {
+		reportErr(err)
+		return
 	}
-	sort.Sort(b)
 
 	w := new(tabwriter.Writer)
 	w.Init(os.Stdout, 10, 8, 0, '\t', 0)
-	fmt.Fprintln(w, "rank\tlogin\temails\tcommits")
+	header := "rank\tlogin\temails\tcommits"
+	if opts.Privacy == PrivacyOmit {
+		header = "rank\tlogin\tcommits"
+	}
+	if opts.CoAuthors {
+		header = strings.TrimSuffix(header, "commits") + "authored\tco-authored\tcredit"
+	}
+	if opts.Enrich {
+		header += profileHeader
+	}
+	var ch *charts
+	if opts.Charts {
+		ch = newCharts(b)
+		header += ch.header()
+	}
+	fmt.Fprintln(w, header)
 
 	total := 0
-	atotal := len(m)
-	for n, v := range b {
+	for _, v := range b {
 		total += v.Count
-		v.Rank = atotal - n
-		fmt.Fprintln(w, v)
+		row := v.String()
+		if opts.Privacy == PrivacyOmit {
+			row = fmt.Sprintf("%d\t%s\t%d", v.Rank, v.who(), v.Count)
+		}
+		if opts.CoAuthors {
+			row += fmt.Sprintf("\t%d\t%.1f", v.CoAuthored, v.score())
+		}
+		if opts.Enrich {
+			row += profileColumns(v)
+		}
+		if ch != nil {
+			row += ch.columns(v)
+		}
+		fmt.Fprintln(w, row)
 	}
 	fmt.Fprintln(w)
 	w.Flush()
 	fmt.Printf("TOTAL COMMITS: %d\n", total)
-	fmt.Printf("TOTAL AUTHORS: %d\n", atotal)
+	fmt.Printf("TOTAL AUTHORS: %d\n", len(b))
+	if opts.CoAuthors {
+		fmt.Printf("TOTAL CO-AUTHORED: %d\n", b.coAuthored())
+	}
+	if n := b.unlinked(); n > 0 {
+		fmt.Printf("AUTHORS WITHOUT GITHUB ACCOUNT: %d\n", n)
+	}
+	opts.printBots(bots, "commits")
 }
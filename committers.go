@@ -0,0 +1,260 @@
+package scrape
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// webFlow is the account GitHub commits changes made in its web UI as,
+// such as PRs merged with the merge button.
+const webFlow = "web-flow"
+
+// mergedPRs looks up the merged PRs commits came through, caching every
+// PR fetched.
+type mergedPRs struct {
+	client    *github.Client
+	org, repo string
+	prs       map[int]*github.PullRequest
+}
+
+// byNumber returns PR n of the repository, nil when it does not exist.
+func (m *mergedPRs) byNumber(n int) (*github.PullRequest, error) {
+	if pr, ok := m.prs[n]; ok {
+		return pr, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	pr, resp, err := m.client.PullRequests.Get(ctx, m.org, m.repo, n)
+	if resp != nil && resp.StatusCode == 404 {
+		pr, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	m.prs[n] = pr
+	return pr, nil
+}
+
+// bySHA returns the merged PR that introduced the commit sha, nil when
+// there is none.
+func (m *mergedPRs) bySHA(sha string) (*github.PullRequest, error) {
+	req, err := m.client.NewRequest("GET", fmt.Sprintf("repos/%s/%s/commits/%s/pulls", m.org, m.repo, sha), nil)
+	if err != nil {
+		return nil, err
+	}
+	// the PRs of a commit are still a preview of the API
+	req.Header.Set("Accept", "application/vnd.github.groot-preview+json")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	var prs []*github.PullRequest
+	if _, err := m.client.Do(ctx, req, &prs); err != nil {
+		return nil, err
+	}
+	for _, pr := range prs {
+		if pr.MergedAt != nil {
+			// the list leaves out who merged it
+			return m.byNumber(pr.GetNumber())
+		}
+	}
+	return nil, nil
+}
+
+// of returns the merged PR commit c came through: the one its message
+// names, or for commits GitHub made without naming one, such as rebase
+// merges, the one the commit belongs to. It is nil for commits pushed
+// directly.
+func (m *mergedPRs) of(c *github.RepositoryCommit) (*github.PullRequest, error) {
+	var pr *github.PullRequest
+	var err error
+	if n := prNumber(c.Commit.GetMessage()); n != 0 {
+		pr, err = m.byNumber(n)
+	} else if c.Committer.GetLogin() == webFlow {
+		pr, err = m.bySHA(c.GetSHA())
+	}
+	if err != nil || pr == nil || pr.MergedBy == nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+// committerStats fetches the commits of a repository in the scope of
+// opts, see repoCommits, and returns them aggregated per account that
+// landed them, ranked and sorted by ascending count: whoever merged the
+// PR a commit came through, else its git committer. The items of every
+// committer name the author of each commit, telling who landed whose
+// work.
+func committerStats(client *github.Client, org, repo string, opts Options) (byCount, error) {
+	commits, err := repoCommits(client, org, repo, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	numbers := make(map[int]bool)
+	unnamed := 0
+	for _, c := range commits {
+		if n := prNumber(c.Commit.GetMessage()); n != 0 {
+			numbers[n] = true
+		} else if c.Committer.GetLogin() == webFlow {
+			unnamed++
+		}
+	}
+	if len(numbers)+unnamed > 0 {
+		log.Printf("looking up who merged %d PRs and the PRs of %d other commits made by %s", len(numbers), unnamed, webFlow)
+	}
+	merged := &mergedPRs{client: client, org: org, repo: repo, prs: make(map[int]*github.PullRequest)}
+
+	// identify returns the map key and stat of an account, falling back to
+	// the git identity of a commit when there is none.
+	identify := func(u *github.User, ca *github.CommitAuthor) (string, *stat) {
+		if u != nil {
+			return accountKey(u), opts.accountStat(u)
+		}
+		if ca == nil {
+			return accountKey(nil), opts.accountStat(nil)
+		}
+		name, e := opts.Mailmap.Lookup(ca.GetName(), ca.GetEmail())
+		name = opts.gitName(name, e)
+		return "email:" + strings.ToLower(e), &stat{Login: name, Unlinked: true, Bot: opts.isBot(name, ""), Email: []string{e}}
+	}
+
+	m := make(map[string]*stat)
+	for _, c := range commits {
+		ak, author := identify(c.Author, c.Commit.Author)
+		k, s := identify(c.Committer, c.Commit.Committer)
+		pr, err := merged.of(c)
+		if err != nil {
+			return nil, err
+		}
+		if pr != nil {
+			k, s = accountKey(pr.MergedBy), opts.accountStat(pr.MergedBy)
+			// merge commits are made by whoever merged, on behalf of
+			// the author of the PR
+			if mergeCommitRE.MatchString(firstLine(c.Commit.GetMessage())) && pr.User != nil {
+				ak, author = accountKey(pr.User), opts.accountStat(pr.User)
+			}
+		}
+		var d time.Time
+		if c.Commit.Committer != nil {
+			d = c.Commit.Committer.GetDate()
+		}
+		it := item{Date: d, Ref: c.GetSHA(), Title: firstLine(c.Commit.GetMessage()), Author: author.who()}
+		if len(it.Ref) > 7 {
+			it.Ref = it.Ref[:7]
+		}
+		if ak == k {
+			it.Author = ""
+		}
+		tmp, ok := m[k]
+		if !ok {
+			tmp = s
+			m[k] = tmp
+		}
+		tmp.Count += 1
+		tmp.Dates = append(tmp.Dates, d)
+		tmp.Items = append(tmp.Items, it)
+		for _, e := range s.Email {
+			if !contains(e, tmp.Email) {
+				tmp.Email = append(tmp.Email, e)
+			}
+		}
+	}
+
+	b := ranked(m)
+	opts.trackLogins(b)
+	return b, nil
+}
+
+// landed counts the commits of s per author other than s itself.
+func landed(s stat) map[string]int {
+	m := make(map[string]int)
+	for _, it := range s.Items {
+		if it.Author != "" {
+			m[it.Author]++
+		}
+	}
+	return m
+}
+
+// Committers prints to stdout the commits to an organization's repository
+// aggregated per committer, telling own commits apart from the ones landed
+// for other authors, followed by which committer landed whose commits.
+func Committers(client *github.Client, org, repo string, opts Options) {
+	b, err := committerStats(client, org, repo, opts)
+	if err != nil {
+		reportErr(err)
+		return
+	}
+	b, bots := opts.filterBots(b)
+	if b, err = enrich(client, b, opts); err != nil {
+		reportErr(err)
+		return
+	}
+
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 10, 8, 0, '\t', 0)
+	header := "rank\tcommitter\tcommits\town\tlanded for others\tauthors"
+	if opts.Enrich {
+		header += profileHeader
+	}
+	var ch *charts
+	if opts.Charts {
+		ch = newCharts(b)
+		header += ch.header()
+	}
+	fmt.Fprintln(w, header)
+	for _, v := range b {
+		others := 0
+		authors := landed(v)
+		for _, n := range authors {
+			others += n
+		}
+		row := fmt.Sprintf("%d\t%s\t%d\t%d\t%d\t%d", v.Rank, v.who(), v.Count, v.Count-others, others, len(authors))
+		if opts.Enrich {
+			row += profileColumns(v)
+		}
+		if ch != nil {
+			row += ch.columns(v)
+		}
+		fmt.Fprintln(w, row)
+	}
+	fmt.Fprintln(w)
+	w.Flush()
+
+	w.Init(os.Stdout, 10, 8, 0, '\t', 0)
+	fmt.Fprintln(w, "LANDED BY")
+	fmt.Fprintln(w, "committer\tauthor\tcommits")
+	for _, v := range b {
+		authors := landed(v)
+		names := make([]string, 0, len(authors))
+		for a := range authors {
+			names = append(names, a)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			if authors[names[i]] != authors[names[j]] {
+				return authors[names[i]] < authors[names[j]]
+			}
+			return names[i] < names[j]
+		})
+		for _, a := range names {
+			fmt.Fprintf(w, "%s\t%s\t%d\n", v.who(), a, authors[a])
+		}
+	}
+	fmt.Fprintln(w)
+	w.Flush()
+	fmt.Printf("TOTAL COMMITS: %d\n", b.total())
+	fmt.Printf("TOTAL COMMITTERS: %d\n", len(b))
+	for _, v := range b {
+		if v.Login == webFlow {
+			fmt.Printf("COMMITTED WITH THE GITHUB WEB UI: %d\n", v.Count)
+		}
+	}
+	opts.printBots(bots, "commits")
+}
@@ -0,0 +1,165 @@
+package scrape
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/google/go-github/github"
+)
+
+// windowStats are the leaderboards of a repository over a period.
+type windowStats struct {
+	commits, opened, merged byCount
+}
+
+// statsIn collects the commits, opened PRs and merged PRs of a repository
+// in p, bots filtered following opts.
+func statsIn(client *github.Client, org, repo string, p Period, opts Options) (windowStats, error) {
+	var ws windowStats
+	var err error
+	opts.Since, opts.Until = p.Start, p.End
+	if ws.commits, err = commitStats(client, org, repo, opts); err != nil {
+		return ws, err
+	}
+	opts.DateField = DateCreated
+	if ws.opened, err = prStats(client, org, repo, "all", opts); err != nil {
+		return ws, err
+	}
+	opts.DateField = DateMerged
+	if ws.merged, err = prStats(client, org, repo, "merged", opts); err != nil {
+		return ws, err
+	}
+	ws.commits, _ = opts.filterBots(ws.commits)
+	ws.opened, _ = opts.filterBots(ws.opened)
+	ws.merged, _ = opts.filterBots(ws.merged)
+	return ws, nil
+}
+
+// contributors returns the contributors of ws by key.
+func (ws windowStats) contributors() map[string]stat {
+	m := make(map[string]stat)
+	for _, b := range []byCount{ws.merged, ws.opened, ws.commits} {
+		for _, s := range b {
+			m[s.key()] = s
+		}
+	}
+	return m
+}
+
+// delta formats the change from before to now, in absolute and relative
+// terms.
+func delta(now, before int) (string, string) {
+	abs := fmt.Sprintf("%+d", now-before)
+	if before == 0 {
+		return abs, "-"
+	}
+	return abs, fmt.Sprintf("%+.1f%%", float64(now-before)*100/float64(before))
+}
+
+// Compare prints to stdout the commits, PRs and contributors of an
+// organization's repository in period next to those in vs, with the
+// change of every metric, the change of rank of every committer and the
+// contributors that are new in period or departed since vs.
+func Compare(client *github.Client, org, repo string, period, vs Period, opts Options) error {
+	now, err := statsIn(client, org, repo, period, opts)
+	if err != nil {
+		return err
+	}
+	before, err := statsIn(client, org, repo, vs, opts)
+	if err != nil {
+		return err
+	}
+	cNow, cBefore := now.contributors(), before.contributors()
+
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 10, 8, 0, '\t', 0)
+	fmt.Fprintf(w, "metric\t%s\t%s\tchange\t%%\n", period, vs)
+	for _, m := range []struct {
+		name        string
+		now, before int
+	}{
+		{"commits", now.commits.total(), before.commits.total()},
+		{"commit authors", len(now.commits), len(before.commits)},
+		{"PRs opened", now.opened.total(), before.opened.total()},
+		{"PRs merged", now.merged.total(), before.merged.total()},
+		{"PR authors", len(now.opened), len(before.opened)},
+		{"contributors", len(cNow), len(cBefore)},
+	} {
+		abs, rel := delta(m.now, m.before)
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\n", m.name, m.now, m.before, abs, rel)
+	}
+	fmt.Fprintln(w)
+	w.Flush()
+
+	// committers of both periods, ranked on the commits of period
+	type row struct {
+		s                   stat
+		now, before         int
+		rankNow, rankBefore int
+	}
+	rows := make(map[string]*row)
+	for _, s := range now.commits {
+		rows[s.key()] = &row{s: s, now: s.Count, rankNow: s.Rank}
+	}
+	for _, s := range before.commits {
+		r, ok := rows[s.key()]
+		if !ok {
+			r = &row{s: s}
+			rows[s.key()] = r
+		}
+		r.before, r.rankBefore = s.Count, s.Rank
+	}
+	list := make([]*row, 0, len(rows))
+	for _, r := range rows {
+		list = append(list, r)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].now != list[j].now {
+			return list[i].now < list[j].now
+		}
+		return list[i].before < list[j].before
+	})
+	w.Init(os.Stdout, 10, 8, 0, '\t', 0)
+	fmt.Fprintln(w, "rank\tlogin\tcommits\tbefore\tchange\trank change")
+	for _, r := range list {
+		rank, move := "-", "departed"
+		switch {
+		case r.rankNow != 0 && r.rankBefore == 0:
+			rank, move = fmt.Sprint(r.rankNow), "new"
+		case r.rankNow != 0 && r.rankNow == r.rankBefore:
+			rank, move = fmt.Sprint(r.rankNow), "="
+		case r.rankNow != 0:
+			rank, move = fmt.Sprint(r.rankNow), fmt.Sprintf("%+d", r.rankBefore-r.rankNow)
+		}
+		abs, _ := delta(r.now, r.before)
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\t%s\n", rank, r.s.who(), r.now, r.before, abs, move)
+	}
+	fmt.Fprintln(w)
+	w.Flush()
+
+	var joined, left []string
+	for k, s := range cNow {
+		if _, ok := cBefore[k]; !ok {
+			joined = append(joined, s.who())
+		}
+	}
+	for k, s := range cBefore {
+		if _, ok := cNow[k]; !ok {
+			left = append(left, s.who())
+		}
+	}
+	sort.Strings(joined)
+	sort.Strings(left)
+	fmt.Printf("NEW CONTRIBUTORS: %d\n", len(joined))
+	if len(joined) > 0 {
+		fmt.Printf("  %s\n", strings.Join(joined, ", "))
+	}
+	fmt.Printf("DEPARTED CONTRIBUTORS: %d\n", len(left))
+	if len(left) > 0 {
+		fmt.Printf("  %s\n", strings.Join(left, ", "))
+	}
+	return nil
+}
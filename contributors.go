@@ -0,0 +1,207 @@
+package scrape
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/github"
+)
+
+// markers delimiting the generated part of a contributors file
+const (
+	contributorsBegin = "<!-- scrape:contributors:begin -->"
+	contributorsEnd   = "<!-- scrape:contributors:end -->"
+)
+
+// contributionType is one section of a contributors file.
+type contributionType struct {
+	title string
+	unit  string
+	// kind is the matching all-contributors contribution type
+	kind  string
+	stats byCount
+}
+
+// allContributor is an entry of an all-contributors config.
+type allContributor struct {
+	Login         string   `json:"login"`
+	Name          string   `json:"name"`
+	AvatarURL     string   `json:"avatar_url"`
+	Profile       string   `json:"profile"`
+	Contributions []string `json:"contributions"`
+}
+
+// UpdateContributorsFile regenerates the list of contributors to an
+// organization's repository in the markdown file at path, grouped by
+// contribution type. Everything outside of the generated section is kept
+// as is, so manual sections survive regeneration. If rc is not empty the
+// all-contributors config at rc is updated as well.
+func UpdateContributorsFile(client *github.Client, org, repo, path, rc string, opts Options) error {
+	commits, err := commitStats(client, org, repo, opts)
+	if err != nil {
+		return err
+	}
+	prs, err := prStats(client, org, repo, "merged", opts)
+	if err != nil {
+		return err
+	}
+	issues, err := issueStats(client, org, repo, opts)
+	if err != nil {
+		return err
+	}
+	commits, _ = opts.filterBots(mergeByEmail(commits))
+	prs, _ = opts.filterBots(prs)
+	issues, _ = opts.filterBots(issues)
+	for _, b := range []*byCount{&commits, &prs, &issues} {
+		if *b, err = enrich(client, *b, opts); err != nil {
+			return err
+		}
+	}
+	types := []contributionType{
+		{title: "Code", unit: "commits", kind: "code", stats: commits},
+		{title: "Pull requests", unit: "merged PRs", kind: "code", stats: prs},
+		{title: "Issues", unit: "issues", kind: "bug", stats: issues},
+	}
+
+	old, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(old) == 0 {
+		old = []byte("# Contributors\n\nThank you to everyone who has contributed to " + org + "/" + repo + "!\n")
+	}
+	doc, err := replaceSection(old, contributorsMarkdown(types))
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	if err := ioutil.WriteFile(path, doc, 0644); err != nil {
+		return err
+	}
+	if rc == "" {
+		return nil
+	}
+	return updateAllContributors(rc, org, repo, types)
+}
+
+// isContributor reports whether s belongs to an actual account.
+func isContributor(s stat) bool {
+	return s.Login != "username missing"
+}
+
+// contributorsMarkdown renders the generated section of a contributors file.
+func contributorsMarkdown(types []contributionType) string {
+	var buf bytes.Buffer
+	for _, t := range types {
+		fmt.Fprintf(&buf, "\n## %s\n\n", t.title)
+		for i := len(t.stats) - 1; i >= 0; i-- {
+			s := t.stats[i]
+			if !isContributor(s) {
+				continue
+			}
+			if s.Unlinked {
+				fmt.Fprintf(&buf, "* %s (%d %s)\n", s.Login, s.Count, t.unit)
+				continue
+			}
+			name := ""
+			if s.Profile != nil && s.Profile.Name != "" {
+				name = " " + s.Profile.Name
+			}
+			fmt.Fprintf(&buf, "* [@%[1]s](https://github.com/%[1]s)%s (%d %s)\n", s.Login, name, s.Count, t.unit)
+		}
+	}
+	return buf.String()
+}
+
+// replaceSection swaps the generated section of doc for section, appending
+// it when doc has none yet. Markers that do not enclose a single section
+// are an error rather than a guess at what was meant.
+func replaceSection(doc []byte, section string) ([]byte, error) {
+	s := string(doc)
+	gen := contributorsBegin + "\n" + section + "\n" + contributorsEnd
+	nb, ne := strings.Count(s, contributorsBegin), strings.Count(s, contributorsEnd)
+	if nb == 0 && ne == 0 {
+		return []byte(strings.TrimRight(s, "\n") + "\n\n" + gen + "\n"), nil
+	}
+	b := strings.Index(s, contributorsBegin)
+	e := strings.Index(s, contributorsEnd)
+	if nb != 1 || ne != 1 || e < b {
+		return nil, fmt.Errorf("want one %s marker followed by one %s marker, found %d and %d", contributorsBegin, contributorsEnd, nb, ne)
+	}
+	return []byte(s[:b] + gen + s[e+len(contributorsEnd):]), nil
+}
+
+// updateAllContributors merges the contributions in types into the
+// all-contributors config at path, keeping its other settings and any
+// contribution types added by hand.
+func updateAllContributors(path, org, repo string, types []contributionType) error {
+	rc := map[string]interface{}{
+		"projectName":         repo,
+		"projectOwner":        org,
+		"repoType":            "github",
+		"repoHost":            "https://github.com",
+		"files":               []string{"README.md"},
+		"imageSize":           100,
+		"commit":              false,
+		"contributorsPerLine": 7,
+	}
+	var contributors []*allContributor
+	old, err := ioutil.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(old, &rc); err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		if raw, ok := rc["contributors"]; ok {
+			b, _ := json.Marshal(raw)
+			if err := json.Unmarshal(b, &contributors); err != nil {
+				return fmt.Errorf("%s: %v", path, err)
+			}
+		}
+	case !os.IsNotExist(err):
+		return err
+	}
+
+	byLogin := make(map[string]*allContributor)
+	for _, c := range contributors {
+		byLogin[c.Login] = c
+	}
+	for _, t := range types {
+		for i := len(t.stats) - 1; i >= 0; i-- {
+			s := t.stats[i]
+			if !isContributor(s) || s.Unlinked {
+				continue
+			}
+			c, ok := byLogin[s.Login]
+			if !ok {
+				c = &allContributor{
+					Login:     s.Login,
+					Name:      s.Login,
+					AvatarURL: "https://github.com/" + s.Login + ".png?size=100",
+					Profile:   "https://github.com/" + s.Login,
+				}
+				byLogin[s.Login] = c
+				contributors = append(contributors, c)
+			}
+			if s.Profile != nil && s.Profile.Name != "" {
+				c.Name = s.Profile.Name
+			}
+			i := sort.SearchStrings(c.Contributions, t.kind)
+			if i == len(c.Contributions) || c.Contributions[i] != t.kind {
+				c.Contributions = append(c.Contributions, t.kind)
+				sort.Strings(c.Contributions)
+			}
+		}
+	}
+	rc["contributors"] = contributors
+
+	out, err := json.MarshalIndent(rc, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(out, '\n'), 0644)
+}
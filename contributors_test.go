@@ -0,0 +1,79 @@
+package scrape
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestReplaceSection(t *testing.T) {
+	begin, end := contributorsBegin, contributorsEnd
+	tests := []struct {
+		doc  string
+		want string
+	}{
+		{"# Contributors\n", "# Contributors\n\n" + begin + "\nnew\n" + end + "\n"},
+		{"# Contributors\n\n\n", "# Contributors\n\n" + begin + "\nnew\n" + end + "\n"},
+		{
+			"intro\n" + begin + "\nold\n" + end + "\noutro\n",
+			"intro\n" + begin + "\nnew\n" + end + "\noutro\n",
+		},
+		{begin + end, begin + "\nnew\n" + end},
+	}
+	for _, tt := range tests {
+		got, err := replaceSection([]byte(tt.doc), "new")
+		if err != nil {
+			t.Errorf("replaceSection(%q): %v", tt.doc, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("replaceSection(%q) = %q, want %q", tt.doc, got, tt.want)
+		}
+	}
+
+	for _, doc := range []string{
+		"intro\n" + begin + "\nold\n",
+		"intro\nold\n" + end + "\n",
+		end + "\nold\n" + begin + "\n",
+		begin + "\nold\n" + end + "\n" + begin + "\nold\n" + end + "\n",
+		begin + "\n" + begin + "\nold\n" + end + "\n",
+	} {
+		if got, err := replaceSection([]byte(doc), "new"); err == nil {
+			t.Errorf("replaceSection(%q) = %q, want an error", doc, got)
+		}
+	}
+}
+
+func TestMergeByEmail(t *testing.T) {
+	b := byCount{
+		{Login: "Jane Doe", Unlinked: true, Count: 9, Email: []string{"jane@home.example"}},
+		{Login: "jdoe", ID: 1, Count: 2, Email: []string{"jane@work.example", "jane@home.example"}},
+		{Login: "jane-alt", ID: 2, Count: 4, Email: []string{"jane@work.example"}},
+		{Login: "bob", ID: 3, Count: 1, Email: []string{"fake@fake.com"}},
+		{Login: "ann", ID: 4, Count: 3, Email: []string{"fake@fake.com"}},
+		{Login: "Joe", Unlinked: true, Count: 1, Email: []string{"joe@example.com"}},
+	}
+	got := make(map[string]stat)
+	for _, s := range mergeByEmail(b) {
+		sort.Strings(s.Email)
+		got[s.Login] = s
+	}
+	var logins []string
+	for l := range got {
+		logins = append(logins, l)
+	}
+	sort.Strings(logins)
+	if want := []string{"Joe", "ann", "bob", "jane-alt"}; !reflect.DeepEqual(logins, want) {
+		t.Fatalf("merged logins %q, want %q", logins, want)
+	}
+	jane := got["jane-alt"]
+	if jane.Count != 15 || jane.ID != 2 || jane.Unlinked {
+		t.Errorf("merged jane = %+v, want 15 commits under account 2", jane)
+	}
+	if want := []string{"jane@home.example", "jane@work.example"}; !reflect.DeepEqual(jane.Email, want) {
+		t.Errorf("merged jane emails %q, want %q", jane.Email, want)
+	}
+	if got["bob"].Count != 1 || got["ann"].Count != 3 {
+		t.Error("placeholder emails should not merge authors")
+	}
+}
@@ -0,0 +1,210 @@
+package scrape
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// rootDir is the directory of the files at the root of a repository.
+const rootDir = "."
+
+// dirOf returns the directory of file cut to its first depth components.
+func dirOf(file string, depth int) string {
+	dir := path.Dir(file)
+	if dir == "." {
+		return rootDir
+	}
+	parts := strings.Split(dir, "/")
+	if len(parts) > depth {
+		parts = parts[:depth]
+	}
+	return strings.Join(parts, "/")
+}
+
+// commitFiles fetches the files touched by each of the commits with the
+// given hashes, one request per commit. On error it returns the files of
+// the commits fetched until then.
+func commitFiles(client *github.Client, org, repo string, shas []string) (map[string][]string, error) {
+	files := make(map[string][]string)
+	for _, sha := range shas {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		c, _, err := client.Repositories.GetCommit(ctx, org, repo, sha)
+		if err != nil {
+			return files, err
+		}
+		for _, f := range c.Files {
+			files[sha] = append(files[sha], f.GetFilename())
+		}
+	}
+	return files, nil
+}
+
+// cloneFiles reads the files touched by the commits reachable from ref,
+// HEAD when empty, with git log in the local clone at dir.
+func cloneFiles(dir, ref string) (map[string][]string, error) {
+	if ref == "" {
+		ref = "HEAD"
+	}
+	cmd := exec.Command("git", "-C", dir, "log", "--name-only", "--format=%x00%H", ref, "--")
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log in %s: %v", dir, err)
+	}
+	files := make(map[string][]string)
+	sha := ""
+	s := bufio.NewScanner(bytes.NewReader(out))
+	for s.Scan() {
+		line := s.Text()
+		switch {
+		case strings.HasPrefix(line, "\x00"):
+			sha = line[1:]
+		case line != "" && sha != "":
+			files[sha] = append(files[sha], line)
+		}
+	}
+	return files, s.Err()
+}
+
+// dirStats splits the commits behind b per directory of the files they
+// touch, cut to depth, and returns a leaderboard for every directory. A
+// commit counts once for each directory it touches.
+func dirStats(b byCount, files map[string][]string, depth int) map[string]byCount {
+	m := make(map[string]map[string]*stat)
+	for _, s := range b {
+		for _, it := range s.Items {
+			touched := make(map[string]bool)
+			for _, f := range files[it.SHA] {
+				touched[dirOf(f, depth)] = true
+			}
+			for dir := range touched {
+				if m[dir] == nil {
+					m[dir] = make(map[string]*stat)
+				}
+				tmp, ok := m[dir][s.key()]
+				if !ok {
+					tmp = &stat{Login: s.Login, ID: s.ID, PreviousLogins: s.PreviousLogins, Unlinked: s.Unlinked, Deleted: s.Deleted, Bot: s.Bot, Email: s.Email}
+					m[dir][s.key()] = tmp
+				}
+				tmp.Count++
+				tmp.Dates = append(tmp.Dates, it.Date)
+				tmp.Items = append(tmp.Items, it)
+			}
+		}
+	}
+	dirs := make(map[string]byCount)
+	for dir, stats := range m {
+		dirs[dir] = ranked(stats)
+	}
+	return dirs
+}
+
+// Dirs prints to stdout a leaderboard of the top contributors to every
+// directory of an organization's repository, cut to depth, followed by
+// the number of contributors every two directories share. The files
+// touched by the commits are read from the local clone at clone when set,
+// else fetched one commit at a time, refusing to make more than
+// maxRequests requests unless it is 0. When the rate limit is hit, only
+// the commits fetched until then are counted.
+func Dirs(client *github.Client, org, repo, clone string, depth, top, maxRequests int, opts Options) error {
+	b, err := commitStats(client, org, repo, opts)
+	if err != nil {
+		reportErr(err)
+		return nil
+	}
+	b, _ = opts.filterBots(b)
+	var files map[string][]string
+	if clone != "" {
+		if files, err = cloneFiles(clone, opts.Ref); err != nil {
+			return err
+		}
+	} else {
+		var shas []string
+		for _, s := range b {
+			for _, it := range s.Items {
+				shas = append(shas, it.SHA)
+			}
+		}
+		if maxRequests > 0 && len(shas) > maxRequests {
+			return fmt.Errorf("finding the files of %d commits takes %d requests, more than -max-requests %d; use -clone to read them from a local clone", len(shas), len(shas), maxRequests)
+		}
+		log.Printf("fetching the files of %d commits, one request each; -clone reads them from a local clone instead", len(shas))
+		if files, err = commitFiles(client, org, repo, shas); err != nil {
+			reportErr(err)
+			log.Printf("only counting the %d of %d commits fetched", len(files), len(shas))
+		}
+	}
+	dirs := dirStats(b, files, depth)
+
+	names := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		names = append(names, dir)
+	}
+	commits := func(dir string) int {
+		return dirs[dir].total()
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if commits(names[i]) != commits(names[j]) {
+			return commits(names[i]) > commits(names[j])
+		}
+		return names[i] < names[j]
+	})
+
+	w := new(tabwriter.Writer)
+	for _, dir := range names {
+		d := dirs[dir]
+		w.Init(os.Stdout, 10, 8, 0, '\t', 0)
+		fmt.Fprintf(w, "%s (%d commits, %d contributors)\n", dir, d.total(), len(d))
+		fmt.Fprintln(w, "rank\tlogin\tcommits\tshare")
+		if top > 0 && len(d) > top {
+			d = d[len(d)-top:]
+		}
+		for _, v := range d {
+			fmt.Fprintf(w, "%d\t%s\t%d\t%d%%\n", v.Rank, v.who(), v.Count, v.Count*100/commits(dir))
+		}
+		fmt.Fprintln(w)
+		w.Flush()
+	}
+
+	w.Init(os.Stdout, 10, 8, 0, '\t', 0)
+	fmt.Fprintln(w, "SHARED CONTRIBUTORS")
+	fmt.Fprintln(w, "\t"+strings.Join(names, "\t"))
+	in := make(map[string]map[string]bool)
+	for _, dir := range names {
+		in[dir] = make(map[string]bool)
+		for _, s := range dirs[dir] {
+			in[dir][s.key()] = true
+		}
+	}
+	for _, a := range names {
+		row := a
+		for _, c := range names {
+			n := 0
+			for k := range in[a] {
+				if in[c][k] {
+					n++
+				}
+			}
+			row += fmt.Sprintf("\t%d", n)
+		}
+		fmt.Fprintln(w, row)
+	}
+	fmt.Fprintln(w)
+	w.Flush()
+	fmt.Printf("TOTAL COMMITS: %d\n", b.total())
+	fmt.Printf("TOTAL DIRECTORIES: %d\n", len(names))
+	return nil
+}
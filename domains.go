@@ -0,0 +1,141 @@
+package scrape
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// email domain classes
+const (
+	classNoreply   = "noreply"
+	classFreeMail  = "free-mail"
+	classCorporate = "corporate"
+	classUnknown   = "unknown"
+)
+
+// trendWindow is the period whose commits are compared with the one before
+// it to show the trend of a domain.
+const trendWindow = 90 * 24 * time.Hour
+
+// FreeMailDomains are the email providers anyone can sign up with.
+var FreeMailDomains = map[string]bool{
+	"gmail.com":      true,
+	"googlemail.com": true,
+	"yahoo.com":      true,
+	"hotmail.com":    true,
+	"outlook.com":    true,
+	"live.com":       true,
+	"msn.com":        true,
+	"icloud.com":     true,
+	"me.com":         true,
+	"mac.com":        true,
+	"aol.com":        true,
+	"protonmail.com": true,
+	"proton.me":      true,
+	"fastmail.com":   true,
+	"hey.com":        true,
+	"zoho.com":       true,
+	"gmx.de":         true,
+	"gmx.net":        true,
+	"web.de":         true,
+	"yandex.ru":      true,
+	"mail.ru":        true,
+	"qq.com":         true,
+	"163.com":        true,
+	"126.com":        true,
+}
+
+// emailDomain returns the lower cased domain of e.
+func emailDomain(e string) string {
+	i := strings.LastIndex(e, "@")
+	if i < 0 {
+		return ""
+	}
+	return strings.ToLower(e[i+1:])
+}
+
+// domainClass tells free-mail, corporate and GitHub noreply domains apart.
+func domainClass(d string) string {
+	switch {
+	case d == "":
+		return classUnknown
+	case d == "users.noreply.github.com" || d == "noreply.github.com":
+		return classNoreply
+	case FreeMailDomains[d]:
+		return classFreeMail
+	}
+	return classCorporate
+}
+
+// trend compares the number of dates in the last trendWindow with the
+// window before it.
+func trend(dates []time.Time, now time.Time) (recent int, change string) {
+	prev := 0
+	for _, d := range dates {
+		switch {
+		case d.After(now.Add(-trendWindow)):
+			recent++
+		case d.After(now.Add(-2 * trendWindow)):
+			prev++
+		}
+	}
+	switch {
+	case prev == 0 && recent == 0:
+		return 0, "-"
+	case prev == 0:
+		return recent, "new"
+	}
+	return recent, fmt.Sprintf("%+d%%", (recent-prev)*100/prev)
+}
+
+// Domains prints to stdout the commits to an organization's repository
+// aggregated per author email domain, with the number of contributors and
+// the trend of the last 90 days for every domain.
+func Domains(client *github.Client, org, repo string, opts Options) {
+	b, err := commitStats(client, org, repo, opts)
+	if err != nil {
+		reportErr(err)
+		return
+	}
+	b, _ = opts.filterBots(b)
+	g := rollup(b, func(s stat, it item) string {
+		return emailDomain(it.Email)
+	})
+
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 10, 8, 0, '\t', 0)
+	header := "rank\tdomain\tclass\tcontributors\tcommits\tlast 90d\ttrend"
+	var ch *charts
+	if opts.Charts {
+		ch = newCharts(g)
+		header += ch.header()
+	}
+	fmt.Fprintln(w, header)
+
+	now := time.Now()
+	classes := make(map[string]int)
+	for _, v := range g {
+		class := domainClass(v.Login)
+		recent, change := trend(v.Dates, now)
+		row := fmt.Sprintf("%d\t%s\t%s\t%d\t%d\t%d\t%s", v.Rank, v.Login, class, len(v.Members), v.Count, recent, change)
+		if ch != nil {
+			row += ch.columns(v)
+		}
+		fmt.Fprintln(w, row)
+		classes[class] += v.Count
+	}
+	fmt.Fprintln(w)
+	w.Flush()
+	fmt.Printf("TOTAL COMMITS: %d\n", g.total())
+	fmt.Printf("TOTAL DOMAINS: %d\n", len(g))
+	for _, class := range []string{classCorporate, classFreeMail, classNoreply, classUnknown} {
+		if n, ok := classes[class]; ok {
+			fmt.Printf("%s: %d commits\n", strings.ToUpper(class), n)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package scrape
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// fork is a fork of a repository compared with its upstream.
+type fork struct {
+	Name    string
+	Ahead   int
+	Behind  int
+	Authors int
+	Pushed  time.Time
+}
+
+// forkStats lists the forks of a repository and compares the default
+// branch of each with the default branch of the repository. Forks never
+// pushed to are not compared, they cannot carry work of their own, and
+// neither are forks whose branch is gone. The number of forks of both
+// kinds is returned as well.
+func forkStats(client *github.Client, org, repo string) (forks []fork, unpushed, gone int, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	upstream, _, err := client.Repositories.Get(ctx, org, repo)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	base := upstream.GetDefaultBranch()
+
+	opt := &github.RepositoryListForksOptions{
+		Sort: "newest",
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		list, resp, err := client.Repositories.ListForks(ctx, org, repo, opt)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		for _, r := range list {
+			pushed := r.GetPushedAt().Time
+			if !pushed.After(r.GetCreatedAt().Time) {
+				unpushed++
+				continue
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			head := r.Owner.GetLogin() + ":" + r.GetDefaultBranch()
+			cmp, resp, err := client.Repositories.CompareCommits(ctx, org, repo, base, head)
+			if resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusUnprocessableEntity) {
+				gone++
+				continue
+			}
+			if err != nil {
+				return nil, 0, 0, err
+			}
+			authors := make(map[string]bool)
+			for _, c := range cmp.Commits {
+				switch {
+				case c.Author != nil:
+					authors[accountKey(c.Author)] = true
+				case c.Commit.Author != nil:
+					authors["email:"+strings.ToLower(c.Commit.Author.GetEmail())] = true
+				}
+			}
+			forks = append(forks, fork{
+				Name:    r.GetFullName(),
+				Ahead:   cmp.GetAheadBy(),
+				Behind:  cmp.GetBehindBy(),
+				Authors: len(authors),
+				Pushed:  pushed,
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.ListOptions.Page = resp.NextPage
+	}
+
+	// forks with the most unique commits first, then the most authors
+	// and the latest push
+	sort.Slice(forks, func(i, j int) bool {
+		a, b := forks[i], forks[j]
+		switch {
+		case a.Ahead != b.Ahead:
+			return a.Ahead > b.Ahead
+		case a.Authors != b.Authors:
+			return a.Authors > b.Authors
+		}
+		return a.Pushed.After(b.Pushed)
+	})
+	return forks, unpushed, gone, nil
+}
+
+// Forks prints to stdout the forks of an organization's repository
+// carrying commits the repository does not have, ranked by the number of
+// those commits, their authors and the last push. With all the forks
+// that are only behind are listed as well.
+func Forks(client *github.Client, org, repo string, all bool) {
+	forks, unpushed, gone, err := forkStats(client, org, repo)
+	if err != nil {
+		reportErr(err)
+		return
+	}
+
+	total := len(forks) + unpushed + gone
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 10, 8, 0, '\t', 0)
+	fmt.Fprintln(w, "rank\tfork\tahead\tbehind\tauthors\tlast push")
+	active := 0
+	for _, f := range forks {
+		if f.Ahead > 0 {
+			active++
+		}
+	}
+	if !all {
+		forks = forks[:active]
+	}
+	// like the leaderboards, the top ranked fork comes last
+	for n := len(forks) - 1; n >= 0; n-- {
+		f := forks[n]
+		rank := "-"
+		if f.Ahead > 0 {
+			rank = fmt.Sprint(n + 1)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%s\n", rank, f.Name, f.Ahead, f.Behind, f.Authors, f.Pushed.Format("2006-01-02"))
+	}
+	fmt.Fprintln(w)
+	w.Flush()
+	fmt.Printf("TOTAL FORKS: %d\n", total)
+	fmt.Printf("FORKS AHEAD: %d\n", active)
+	fmt.Printf("FORKS NEVER PUSHED TO: %d\n", unpushed)
+	if gone > 0 {
+		fmt.Printf("FORKS WITHOUT THEIR BRANCH: %d\n", gone)
+	}
+}
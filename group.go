@@ -0,0 +1,125 @@
+package scrape
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/google/go-github/github"
+)
+
+// rollup aggregates the contributions behind b into the group groupOf
+// assigns to each of them. Members of the resulting stats are the
+// contributors rolled up into the group.
+func rollup(b byCount, groupOf func(s stat, it item) string) byCount {
+	m := make(map[string]*stat)
+	members := make(map[string]map[string]bool)
+	for _, s := range b {
+		for _, it := range s.Items {
+			g := groupOf(s, it)
+			tmp, ok := m[g]
+			if !ok {
+				tmp = &stat{Login: g, Email: []string{}}
+				m[g] = tmp
+				members[g] = make(map[string]bool)
+			}
+			tmp.Count++
+			tmp.Dates = append(tmp.Dates, it.Date)
+			tmp.Items = append(tmp.Items, it)
+			if !members[g][s.who()] {
+				members[g][s.who()] = true
+				tmp.Members = append(tmp.Members, s.who())
+			}
+		}
+	}
+	return ranked(m)
+}
+
+// byCompany rolls b up per company using opts.Affiliations, falling back to
+// the company on the GitHub profile of the contributor when
+// opts.ProfileCompanies is set. Profiles are cached like with
+// opts.Enrich. PRs and reviews do not carry the email of their author, so
+// only the login lines of the affiliations apply to them.
+func byCompany(client *github.Client, b byCount, opts Options) (byCount, error) {
+	if opts.Affiliations.byEmail() && len(b) > 0 && !b.hasEmails() {
+		log.Printf("warning: only the @login lines of -affiliations apply here, as these contributions carry no author emails; -profile-company can map the rest")
+	}
+	profile := make(map[string]string)
+	if opts.ProfileCompanies {
+		m, err := profiles(client, b, opts)
+		if err != nil {
+			return nil, err
+		}
+		for l, p := range m {
+			profile[l] = p.Company
+		}
+	}
+	return rollup(b, func(s stat, it item) string {
+		login := s.Login
+		if s.Unlinked {
+			login = ""
+		}
+		if c, ok := opts.Affiliations.Company(login, it.Email, it.Date); ok {
+			return c
+		}
+		if c := profile[login]; c != "" {
+			return c
+		}
+		return unknownCompany
+	}), nil
+}
+
+// byGroup rolls b up per opts.By.
+func byGroup(client *github.Client, b byCount, opts Options) (byCount, error) {
+	if opts.By == "team" {
+		return byTeam(b, opts), nil
+	}
+	return byCompany(client, b, opts)
+}
+
+// normalizeCompany cleans up the free form company of a GitHub profile.
+func normalizeCompany(c string) string {
+	return strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(c), "@"))
+}
+
+// formatCount formats a count that may have been split into shares,
+// rounded to two decimals.
+func formatCount(f float64) string {
+	return strconv.FormatFloat(math.Round(f*100)/100, 'f', -1, 64)
+}
+
+// printGroups prints to stdout the stats b rolled up per opts.By, one
+// group per row.
+func printGroups(b byCount, unit string, opts Options) {
+	groups := "companies"
+	if opts.By == "team" {
+		groups = "teams"
+	}
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 10, 8, 0, '\t', 0)
+	header := fmt.Sprintf("rank\t%s\tcontributors\t%s", opts.By, unit)
+	var ch *charts
+	if opts.Charts {
+		ch = newCharts(b)
+		header += ch.header()
+	}
+	fmt.Fprintln(w, header)
+
+	total := 0.0
+	for _, v := range b {
+		total += v.score()
+		row := fmt.Sprintf("%d\t%s\t%d\t%s", v.Rank, v.Login, len(v.Members), formatCount(v.score()))
+		if ch != nil {
+			row += ch.columns(v)
+		}
+		fmt.Fprintln(w, row)
+	}
+	fmt.Fprintln(w)
+	w.Flush()
+	fmt.Printf("TOTAL %s: %s\n", strings.ToUpper(unit), formatCount(total))
+	fmt.Printf("TOTAL %s: %d\n", strings.ToUpper(groups), len(b))
+}
@@ -0,0 +1,172 @@
+package scrape
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/go-github/github"
+)
+
+// GitHub shows the contributions of deleted accounts as this user.
+const (
+	ghostID    = 10137891
+	ghostLogin = "ghost"
+)
+
+// accountKey returns the key contributions of u are aggregated under: its
+// numeric ID, which survives login renames. All deleted accounts share
+// the ghost user and its own key.
+func accountKey(u *github.User) string {
+	if u == nil {
+		return "username missing"
+	}
+	if u.GetID() == ghostID || u.GetID() == 0 && u.GetLogin() == ghostLogin {
+		return ghostLogin
+	}
+	if u.GetID() == 0 {
+		return "login:" + u.GetLogin()
+	}
+	return "id:" + strconv.Itoa(u.GetID())
+}
+
+// noreplyDomain is the domain of the private commit emails GitHub gives
+// its users.
+const noreplyDomain = "@users.noreply.github.com"
+
+// noreplyUser returns the account behind a GitHub noreply email, either
+// ID+login@users.noreply.github.com or the older
+// login@users.noreply.github.com, and nil for any other email.
+func noreplyUser(e string) *github.User {
+	if len(e) <= len(noreplyDomain) || !strings.EqualFold(e[len(e)-len(noreplyDomain):], noreplyDomain) {
+		return nil
+	}
+	login := e[:len(e)-len(noreplyDomain)]
+	id := 0
+	if i := strings.Index(login, "+"); i >= 0 {
+		n, err := strconv.Atoi(login[:i])
+		if err != nil || n <= 0 || login[i+1:] == "" {
+			return nil
+		}
+		id, login = n, login[i+1:]
+	}
+	u := &github.User{Login: &login}
+	if id != 0 {
+		u.ID = &id
+	}
+	return u
+}
+
+// accountStat returns an empty stat for the contributions of u, which may
+// be nil when the API did not say who made them.
+func (o Options) accountStat(u *github.User) *stat {
+	if u == nil {
+		return &stat{Login: "username missing", Email: []string{}}
+	}
+	return &stat{
+		Login:   u.GetLogin(),
+		ID:      u.GetID(),
+		Deleted: accountKey(u) == ghostLogin,
+		Bot:     o.isBot(u.GetLogin(), u.GetType()),
+		Email:   []string{},
+	}
+}
+
+// loginRegistry remembers every login seen for an account ID across runs.
+type loginRegistry struct {
+	path   string
+	Logins map[int][]string `json:"logins"`
+}
+
+// loginsMu serializes access to the registry file by collectors running
+// concurrently, like the tabs of Browse.
+var loginsMu sync.Mutex
+
+// trackLogins records the current login of every account in b in the
+// registry kept in opts.CacheDir, and sets the previous logins seen for
+// them, when opts.TrackLogins is set. The registry only adds to reports,
+// so failing to read or write it is logged and otherwise ignored.
+func (o Options) trackLogins(b byCount) {
+	if !o.TrackLogins {
+		return
+	}
+	dir := o.CacheDir
+	if dir == "" {
+		dir = defaultCacheDir()
+	}
+	loginsMu.Lock()
+	defer loginsMu.Unlock()
+	r := &loginRegistry{
+		path:   filepath.Join(dir, "logins.json"),
+		Logins: make(map[int][]string),
+	}
+	buf, err := ioutil.ReadFile(r.path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(buf, r); err != nil {
+			log.Printf("ignoring login registry %s: %v", r.path, err)
+			r.Logins = make(map[int][]string)
+		}
+	case !os.IsNotExist(err):
+		log.Printf("ignoring login registry: %v", err)
+	}
+
+	changed := false
+	for n, s := range b {
+		if s.ID == 0 || s.ID == ghostID {
+			continue
+		}
+		seen := r.Logins[s.ID]
+		var prev []string
+		for _, l := range seen {
+			if l != s.Login {
+				prev = append(prev, l)
+			}
+		}
+		b[n].PreviousLogins = prev
+		if !contains(s.Login, seen) {
+			r.Logins[s.ID] = append(seen, s.Login)
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+	if err := r.save(); err != nil {
+		log.Printf("saving login registry: %v", err)
+	}
+}
+
+// save writes the registry back to disk through a temporary file, so that
+// an interrupted write never leaves a truncated registry behind.
+func (r *loginRegistry) save() error {
+	dir := filepath.Dir(r.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	buf, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(dir, "logins-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(buf); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), r.path)
+}
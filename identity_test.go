@@ -0,0 +1,58 @@
+package scrape
+
+import (
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestNoreplyUser(t *testing.T) {
+	tests := []struct {
+		email string
+		id    int
+		login string
+		ok    bool
+	}{
+		{"1234567+jdoe@users.noreply.github.com", 1234567, "jdoe", true},
+		{"1234567+JDoe@Users.NoReply.GitHub.com", 1234567, "JDoe", true},
+		{"jdoe@users.noreply.github.com", 0, "jdoe", true},
+		{"49699333+dependabot[bot]@users.noreply.github.com", 49699333, "dependabot[bot]", true},
+		{"jane@example.com", 0, "", false},
+		{"noreply@github.com", 0, "", false},
+		{"@users.noreply.github.com", 0, "", false},
+		{"x+jdoe@users.noreply.github.com", 0, "", false},
+		{"1234567+@users.noreply.github.com", 0, "", false},
+		{"", 0, "", false},
+	}
+	for _, tt := range tests {
+		u := noreplyUser(tt.email)
+		if (u != nil) != tt.ok {
+			t.Errorf("noreplyUser(%q) = %v, want ok %v", tt.email, u, tt.ok)
+			continue
+		}
+		if u != nil && (u.GetID() != tt.id || u.GetLogin() != tt.login) {
+			t.Errorf("noreplyUser(%q) = %d %q, want %d %q", tt.email, u.GetID(), u.GetLogin(), tt.id, tt.login)
+		}
+	}
+}
+
+func TestAccountKey(t *testing.T) {
+	id, ghost, login := 42, ghostID, "jdoe"
+	ghostName := ghostLogin
+	tests := []struct {
+		u    *github.User
+		want string
+	}{
+		{nil, "username missing"},
+		{&github.User{ID: &id, Login: &login}, "id:42"},
+		{&github.User{Login: &login}, "login:jdoe"},
+		{&github.User{ID: &ghost, Login: &ghostName}, "ghost"},
+		{&github.User{Login: &ghostName}, "ghost"},
+		{noreplyUser("42+jdoe-renamed@users.noreply.github.com"), "id:42"},
+	}
+	for _, tt := range tests {
+		if got := accountKey(tt.u); got != tt.want {
+			t.Errorf("accountKey(%v) = %q, want %q", tt.u, got, tt.want)
+		}
+	}
+}
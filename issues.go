@@ -0,0 +1,59 @@
+package scrape
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// issueStats fetches every issue, but not PRs, of a repository and returns
+// them aggregated per author, ranked and sorted by ascending count. Only
+// the issues created between opts.Since and opts.Until are counted.
+func issueStats(client *github.Client, org, repo string, opts Options) (byCount, error) {
+	opt := &github.IssueListByRepoOptions{
+		State: "all",
+		Since: opts.Since,
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+
+	m := make(map[string]*stat)
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		issues, resp, err := client.Issues.ListByRepo(ctx, org, repo, opt)
+		if err != nil {
+			return nil, err
+		}
+		for _, i := range issues {
+			if i.PullRequestLinks != nil {
+				continue
+			}
+			d := i.GetCreatedAt()
+			if !opts.within(d) {
+				continue
+			}
+			k := accountKey(i.User)
+			it := item{Date: d, Ref: fmt.Sprintf("#%d", i.GetNumber()), Title: i.GetTitle()}
+			tmp, ok := m[k]
+			if !ok {
+				tmp = opts.accountStat(i.User)
+				m[k] = tmp
+			}
+			tmp.Count += 1
+			tmp.Dates = append(tmp.Dates, d)
+			tmp.Items = append(tmp.Items, it)
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.ListOptions.Page = resp.NextPage
+	}
+
+	b := ranked(m)
+	opts.trackLogins(b)
+	return b, nil
+}
@@ -0,0 +1,144 @@
+package scrape
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// Mailmap canonicalizes author names and emails the way git does with a
+// .mailmap file. See gitmailmap(5) for the format.
+type Mailmap struct {
+	byEmail     map[string]mailmapEntry
+	byNameEmail map[string]mailmapEntry
+}
+
+// mailmapEntry is the proper name and email of an identity. Either may be
+// empty when the mailmap line only corrects the other.
+type mailmapEntry struct {
+	name, email string
+}
+
+// merge returns e with the fields set in o replaced, as later lines of a
+// mailmap refine earlier ones.
+func (e mailmapEntry) merge(o mailmapEntry) mailmapEntry {
+	if o.name != "" {
+		e.name = o.name
+	}
+	if o.email != "" {
+		e.email = o.email
+	}
+	return e
+}
+
+func mailmapKey(name, email string) string {
+	return strings.ToLower(name) + "\x00" + strings.ToLower(email)
+}
+
+// ParseMailmap reads a mailmap from r.
+func ParseMailmap(r io.Reader) (*Mailmap, error) {
+	m := &Mailmap{
+		byEmail:     make(map[string]mailmapEntry),
+		byNameEmail: make(map[string]mailmapEntry),
+	}
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := s.Text()
+		if i := strings.LastIndex(line, "#"); i >= 0 && i > strings.LastIndex(line, ">") {
+			line = line[:i]
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var names, emails []string
+		for {
+			i := strings.Index(line, "<")
+			if i < 0 {
+				break
+			}
+			j := strings.Index(line[i:], ">")
+			if j < 0 {
+				return nil, fmt.Errorf("mailmap line %d: unterminated email", n)
+			}
+			names = append(names, strings.TrimSpace(line[:i]))
+			emails = append(emails, strings.TrimSpace(line[i+1:i+j]))
+			line = line[i+j+1:]
+		}
+		switch len(emails) {
+		case 1:
+			// Proper Name <commit@email>
+			k := strings.ToLower(emails[0])
+			m.byEmail[k] = m.byEmail[k].merge(mailmapEntry{name: names[0]})
+		case 2:
+			// [Proper Name] <proper@email> [Commit Name] <commit@email>
+			e := mailmapEntry{name: names[0], email: emails[0]}
+			if names[1] != "" {
+				k := mailmapKey(names[1], emails[1])
+				m.byNameEmail[k] = m.byNameEmail[k].merge(e)
+				continue
+			}
+			k := strings.ToLower(emails[1])
+			m.byEmail[k] = m.byEmail[k].merge(e)
+		default:
+			return nil, fmt.Errorf("mailmap line %d: want one or two emails, got %d", n, len(emails))
+		}
+	}
+	return m, s.Err()
+}
+
+// LoadMailmap reads the mailmap file at path.
+func LoadMailmap(path string) (*Mailmap, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseMailmap(f)
+}
+
+// FetchMailmap reads the .mailmap at the root of an organization's
+// repository. A repository without one yields an empty mailmap.
+func FetchMailmap(client *github.Client, org, repo string) (*Mailmap, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	f, _, resp, err := client.Repositories.GetContents(ctx, org, repo, ".mailmap", nil)
+	if resp != nil && resp.StatusCode == 404 {
+		return ParseMailmap(strings.NewReader(""))
+	}
+	if err != nil {
+		return nil, err
+	}
+	content, err := f.GetContent()
+	if err != nil {
+		return nil, err
+	}
+	return ParseMailmap(strings.NewReader(content))
+}
+
+// Lookup returns the canonical name and email for a commit identity. A nil
+// Mailmap returns them unchanged.
+func (m *Mailmap) Lookup(name, email string) (string, string) {
+	if m == nil {
+		return name, email
+	}
+	e, ok := m.byNameEmail[mailmapKey(name, email)]
+	if !ok {
+		e, ok = m.byEmail[strings.ToLower(email)]
+	}
+	if !ok {
+		return name, email
+	}
+	if e.name != "" {
+		name = e.name
+	}
+	if e.email != "" {
+		email = e.email
+	}
+	return name, email
+}
@@ -0,0 +1,73 @@
+package scrape
+
+import (
+	"strings"
+	"testing"
+)
+
+const testMailmap = `# a comment
+Jane Doe <jane@example.com>
+<jane@example.com> <jane@old.example.com>
+Jane Doe <jane@example.com> jdoe <jdoe@laptop.local>
+Joe Developer <joe@example.com> Joe <Joe@Example.org>   # trailing comment
+Other Author <other@example.com> nick2 <bugs@example.com>
+Other Author <other@example.com>
+
+<bot@example.com> <Bot@CI.example.com>
+Bot Name <bot@ci.example.com>
+`
+
+func TestMailmapLookup(t *testing.T) {
+	m, err := ParseMailmap(strings.NewReader(testMailmap))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name, email         string
+		wantName, wantEmail string
+	}{
+		// name only
+		{"jane", "jane@example.com", "Jane Doe", "jane@example.com"},
+		{"jane", "JANE@example.com", "Jane Doe", "JANE@example.com"},
+		// email only
+		{"jane", "jane@old.example.com", "jane", "jane@example.com"},
+		// name and email, matched on both
+		{"jdoe", "jdoe@laptop.local", "Jane Doe", "jane@example.com"},
+		{"JDoe", "JDOE@laptop.local", "Jane Doe", "jane@example.com"},
+		{"someone", "jdoe@laptop.local", "someone", "jdoe@laptop.local"},
+		{"Joe", "joe@example.org", "Joe Developer", "joe@example.com"},
+		// name and email lines take precedence over email lines
+		{"nick2", "bugs@example.com", "Other Author", "other@example.com"},
+		{"nick1", "bugs@example.com", "nick1", "bugs@example.com"},
+		// later lines refine earlier ones
+		{"ci", "bot@ci.example.com", "Bot Name", "bot@example.com"},
+		// unmapped
+		{"Ann", "ann@example.com", "Ann", "ann@example.com"},
+		{"", "", "", ""},
+	}
+	for _, tt := range tests {
+		name, email := m.Lookup(tt.name, tt.email)
+		if name != tt.wantName || email != tt.wantEmail {
+			t.Errorf("Lookup(%q, %q) = %q, %q, want %q, %q", tt.name, tt.email, name, email, tt.wantName, tt.wantEmail)
+		}
+	}
+}
+
+func TestMailmapNil(t *testing.T) {
+	var m *Mailmap
+	if name, email := m.Lookup("Jane", "jane@example.com"); name != "Jane" || email != "jane@example.com" {
+		t.Errorf("nil Lookup = %q, %q, want the identity unchanged", name, email)
+	}
+}
+
+func TestParseMailmapErrors(t *testing.T) {
+	for _, in := range []string{
+		"Jane Doe <jane@example.com",
+		"Jane Doe jane@example.com",
+		"A <a@example.com> B <b@example.com> C <c@example.com>",
+	} {
+		if _, err := ParseMailmap(strings.NewReader(in)); err == nil {
+			t.Errorf("ParseMailmap(%q) succeeded, want an error", in)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package scrape
+
+import (
+	"regexp"
+	"time"
+)
+
+// Options controls what is collected and how it is printed by the commands
+// in this package. The zero value reproduces the plain tables.
+type Options struct {
+	// Charts adds a sparkline of weekly activity and a bar scaled to the
+	// leader to every row.
+	Charts bool
+
+	// Since and Until, when set, restrict contributions to those made
+	// from Since on and before Until. PRs are selected on their
+	// DateField, one of DateCreated (the default), DateMerged or
+	// DateClosed.
+	Since     time.Time
+	Until     time.Time
+	DateField string
+	// Bucket, one of BucketWeek, BucketMonth or BucketQuarter, replaces
+	// the commit, PR and review leaderboards with the activity of every
+	// contributor per period.
+	Bucket string
+
+	// Ref, Paths and Author scope commits to those reachable from the
+	// branch, tag or SHA Ref instead of the default branch, touching any
+	// of Paths and written by the login or email Author.
+	Ref    string
+	Paths  []string
+	Author string
+
+	// Mailmap, when set, canonicalizes commit author names and emails
+	// before commits are aggregated.
+	Mailmap *Mailmap
+
+	// ResolveEmails looks up the GitHub account of commit authors whose
+	// commits are not linked to one, using the user search API.
+	ResolveEmails bool
+
+	// By rolls the commit, PR and review leaderboards up per group of
+	// contributors instead of listing them one by one. The groups are
+	// "company" and "team".
+	By string
+
+	// Affiliations maps contributors to companies when By is "company".
+	Affiliations *Affiliations
+
+	// ProfileCompanies uses the company on the GitHub profile of
+	// contributors that Affiliations does not map.
+	ProfileCompanies bool
+
+	// Teams maps contributors to teams when By is "team". TeamPolicy is
+	// how contributors in several teams are counted, one of TeamSplit,
+	// TeamDuplicate or TeamPrimary.
+	Teams      *Teams
+	TeamPolicy string
+
+	// ExcludeBots drops bots from the results, OnlyBots drops everyone
+	// else. Accounts are bots when their login ends in [bot], their
+	// account type is Bot or their login matches one of BotPatterns.
+	ExcludeBots bool
+	OnlyBots    bool
+
+	// BotPatterns match the logins of bots. Nil means
+	// DefaultBotPatterns.
+	BotPatterns []*regexp.Regexp
+
+	// Privacy keeps contributor emails out of the output: PrivacyOmit
+	// leaves them out, PrivacyMask keeps only their first letter and
+	// domain and PrivacyHash replaces them with a hash salted with Salt,
+	// so the same email always hashes the same way.
+	Privacy string
+	Salt    string
+
+	// CoAuthors credits the people named in the Trailers of commit
+	// messages, CoAuthorWeight per commit, next to the commit author.
+	CoAuthors      bool
+	Trailers       []string
+	CoAuthorWeight float64
+
+	// Enrich adds the name, company, location, creation date and
+	// followers from the GitHub profile of every contributor. Profiles
+	// are cached in CacheDir, the user cache directory when empty, and
+	// fetched again after a week.
+	Enrich   bool
+	CacheDir string
+
+	// TrackLogins remembers the logins of every account in CacheDir
+	// across runs, so that accounts renamed since are shown with their
+	// earlier logins.
+	TrackLogins bool
+}
+
+// trailers returns the commit trailers crediting co-authors.
+func (o Options) trailers() []string {
+	if len(o.Trailers) == 0 {
+		return DefaultTrailers
+	}
+	return o.Trailers
+}
@@ -0,0 +1,246 @@
+package scrape
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// Repo is a repository of a user or organization.
+type Repo struct {
+	Owner, Name string
+}
+
+func (r Repo) String() string {
+	return r.Owner + "/" + r.Name
+}
+
+// RepoFilter selects the repositories of an organization to aggregate.
+type RepoFilter struct {
+	// Archived and Forks include archived repositories and forks, which
+	// are left out by default.
+	Archived, Forks bool
+	// Visibility is one of "all", "public" or "private".
+	Visibility string
+	// Topics are topics every repository must have.
+	Topics []string
+	// Match, when set, must match the repository name.
+	Match *regexp.Regexp
+}
+
+// orgRepo is the part of a listed repository RepoFilter looks at. The
+// vendored github.Repository has no Archived field.
+type orgRepo struct {
+	Name     string   `json:"name"`
+	Fork     bool     `json:"fork"`
+	Archived bool     `json:"archived"`
+	Private  bool     `json:"private"`
+	Topics   []string `json:"topics"`
+}
+
+func (f RepoFilter) match(r orgRepo) bool {
+	switch {
+	case r.Archived && !f.Archived, r.Fork && !f.Forks:
+		return false
+	case f.Visibility == "public" && r.Private, f.Visibility == "private" && !r.Private:
+		return false
+	case f.Match != nil && !f.Match.MatchString(r.Name):
+		return false
+	}
+	for _, t := range f.Topics {
+		if !contains(t, r.Topics) {
+			return false
+		}
+	}
+	return true
+}
+
+// ListOrgRepos returns the repositories of org matching f. org may be a
+// user as well.
+func ListOrgRepos(client *github.Client, org string, f RepoFilter) ([]Repo, error) {
+	repos, err := listOwnerRepos(client, "orgs", org, f)
+	if e, ok := err.(*github.ErrorResponse); ok && e.Response.StatusCode == http.StatusNotFound {
+		return listOwnerRepos(client, "users", org, f)
+	}
+	return repos, err
+}
+
+// listOwnerRepos lists the repositories of an owner of the given kind,
+// "orgs" or "users".
+func listOwnerRepos(client *github.Client, kind, owner string, f RepoFilter) ([]Repo, error) {
+	var repos []Repo
+	for page := 1; page != 0; {
+		req, err := client.NewRequest("GET", fmt.Sprintf("%s/%s/repos?per_page=100&page=%d", kind, owner, page), nil)
+		if err != nil {
+			return nil, err
+		}
+		// topics are still a preview of the API
+		req.Header.Set("Accept", "application/vnd.github.mercy-preview+json")
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		var list []orgRepo
+		resp, err := client.Do(ctx, req, &list)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range list {
+			if f.match(r) {
+				repos = append(repos, Repo{Owner: owner, Name: r.Name})
+			}
+		}
+		page = resp.NextPage
+	}
+	return repos, nil
+}
+
+// key returns the key s is merged under with the stats of other
+// repositories.
+func (s stat) key() string {
+	switch {
+	case s.Deleted:
+		return ghostLogin
+	case s.ID != 0:
+		return "id:" + strconv.Itoa(s.ID)
+	case s.Unlinked && len(s.Email) > 0:
+		return "email:" + strings.ToLower(s.Email[0])
+	}
+	return "login:" + s.Login
+}
+
+// across collects the stats of every repository in repos and merges them
+// per contributor, the items tagged with their repository. Empty
+// repositories are skipped.
+func across(repos []Repo, collect func(r Repo) (byCount, error)) (byCount, error) {
+	m := make(map[string]*stat)
+	for _, r := range repos {
+		b, err := collect(r)
+		if e, ok := err.(*github.ErrorResponse); ok && e.Response.StatusCode == http.StatusConflict {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", r, err)
+		}
+		for _, s := range b {
+			k := s.key()
+			tmp, ok := m[k]
+			if !ok {
+				tmp = &stat{Login: s.Login, ID: s.ID, PreviousLogins: s.PreviousLogins, Unlinked: s.Unlinked, Deleted: s.Deleted, Bot: s.Bot, Email: []string{}}
+				m[k] = tmp
+			}
+			tmp.Count += s.Count
+			tmp.CoAuthored += s.CoAuthored
+			tmp.Credit += s.Credit
+			tmp.Dates = append(tmp.Dates, s.Dates...)
+			for _, it := range s.Items {
+				it.Repo = r.String()
+				tmp.Items = append(tmp.Items, it)
+			}
+			for _, e := range s.Email {
+				if !contains(e, tmp.Email) {
+					tmp.Email = append(tmp.Email, e)
+				}
+			}
+		}
+	}
+	return ranked(m), nil
+}
+
+// GetAllCommitsAcross prints to stdout a sorted list of all commits to
+// every repository in repos, followed by the commits of every contributor
+// per repository.
+func GetAllCommitsAcross(client *github.Client, repos []Repo, opts Options) {
+	b, err := across(repos, func(r Repo) (byCount, error) {
+		return commitStats(client, r.Owner, r.Name, opts)
+	})
+	if err != nil {
+		reportErr(err)
+		return
+	}
+	printCommits(client, b, opts)
+	if opts.By == "" {
+		printRepoMatrix(b, opts)
+	}
+}
+
+// GetPRsAcross prints to stdout a sorted list of either closed or open PRs
+// to every repository in repos, followed by the PRs of every contributor
+// per repository.
+func GetPRsAcross(client *github.Client, repos []Repo, state string, opts Options) {
+	b, err := across(repos, func(r Repo) (byCount, error) {
+		return prStats(client, r.Owner, r.Name, state, opts)
+	})
+	if err != nil {
+		reportErr(err)
+		return
+	}
+	printPRs(client, b, opts)
+	if opts.By == "" {
+		printRepoMatrix(b, opts)
+	}
+}
+
+// printRepoMatrix prints to stdout the contributions of every contributor
+// in b per repository, the busiest repositories first. Repositories are
+// named without their owner when they all share one.
+func printRepoMatrix(b byCount, opts Options) {
+	b, _ = opts.filterBots(b)
+	totals := make(map[string]int)
+	owners := make(map[string]bool)
+	for _, s := range b {
+		for _, it := range s.Items {
+			totals[it.Repo]++
+			owners[strings.SplitN(it.Repo, "/", 2)[0]] = true
+		}
+	}
+	var repos []string
+	for r := range totals {
+		repos = append(repos, r)
+	}
+	sort.Slice(repos, func(i, j int) bool {
+		if totals[repos[i]] != totals[repos[j]] {
+			return totals[repos[i]] > totals[repos[j]]
+		}
+		return repos[i] < repos[j]
+	})
+
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 10, 8, 0, '\t', 0)
+	header := "rank\tlogin"
+	for _, r := range repos {
+		if len(owners) == 1 {
+			r = strings.SplitN(r, "/", 2)[1]
+		}
+		header += "\t" + r
+	}
+	fmt.Fprintln(w, header+"\ttotal")
+	for _, s := range b {
+		counts := make(map[string]int)
+		for _, it := range s.Items {
+			counts[it.Repo]++
+		}
+		row := fmt.Sprintf("%d\t%s", s.Rank, s.who())
+		for _, r := range repos {
+			row += fmt.Sprintf("\t%d", counts[r])
+		}
+		fmt.Fprintf(w, "%s\t%d\n", row, len(s.Items))
+	}
+	row := "\tTOTAL"
+	total := 0
+	for _, r := range repos {
+		row += fmt.Sprintf("\t%d", totals[r])
+		total += totals[r]
+	}
+	fmt.Fprintf(w, "%s\t%d\n", row, total)
+	fmt.Fprintln(w)
+	w.Flush()
+	fmt.Printf("TOTAL REPOSITORIES: %d\n", len(repos))
+}
@@ -0,0 +1,177 @@
+package scrape
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// Dates of PRs the date range of Options selects them on.
+const (
+	DateCreated = "created"
+	DateMerged  = "merged"
+	DateClosed  = "closed"
+)
+
+// Period is the time from Start up to, but not including, End.
+type Period struct {
+	Start, End time.Time
+}
+
+func (p Period) String() string {
+	return p.Start.Format("2006-01-02") + " to " + p.End.Add(-time.Nanosecond).Format("2006-01-02")
+}
+
+// Previous returns the period of the same length right before p. Periods
+// of whole months, such as a quarter, step back by as many calendar
+// months, and periods of whole days by as many days. Other periods step
+// back by their duration.
+func (p Period) Previous() Period {
+	if midnight(p.Start) && midnight(p.End) {
+		if p.Start.Day() == 1 && p.End.Day() == 1 {
+			months := 12*(p.End.Year()-p.Start.Year()) + int(p.End.Month()-p.Start.Month())
+			return Period{p.Start.AddDate(0, -months, 0), p.Start}
+		}
+		days := int(p.End.Sub(p.Start).Hours()/24 + 0.5)
+		return Period{p.Start.AddDate(0, 0, -days), p.Start}
+	}
+	return Period{p.Start.Add(-p.End.Sub(p.Start)), p.Start}
+}
+
+// midnight reports whether t is the start of a day.
+func midnight(t time.Time) bool {
+	return t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0
+}
+
+var (
+	agoRE     = regexp.MustCompile(`^(\d+)([dwmy])$`)
+	quarterRE = regexp.MustCompile(`^(\d{4})-[Qq]([1-4])$`)
+)
+
+// quarterOf returns the first day of the quarter of t.
+func quarterOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month()-(t.Month()-1)%3, 1, 0, 0, 0, 0, t.Location())
+}
+
+// ParsePeriod parses a point or span of time relative to now. It accepts
+//
+//	2026-07-01                a day
+//	2026-07, 2026-Q3, 2026    a month, quarter or year
+//	90d, 12w, 6m, 1y          the days, weeks, months or years up to now
+//	today, yesterday
+//	this-week, this-month, this-quarter, this-year
+//	last-week, last-month, last-quarter, last-year
+//
+// Weeks start on Monday.
+func ParsePeriod(s string, now time.Time) (Period, error) {
+	loc := now.Location()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	week := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+	quarter := quarterOf(today)
+	year := time.Date(now.Year(), 1, 1, 0, 0, 0, 0, loc)
+
+	if m := agoRE.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		var start time.Time
+		switch m[2] {
+		case "d":
+			start = now.AddDate(0, 0, -n)
+		case "w":
+			start = now.AddDate(0, 0, -7*n)
+		case "m":
+			start = now.AddDate(0, -n, 0)
+		case "y":
+			start = now.AddDate(-n, 0, 0)
+		}
+		return Period{start, now}, nil
+	}
+	if m := quarterRE.FindStringSubmatch(s); m != nil {
+		y, _ := strconv.Atoi(m[1])
+		q, _ := strconv.Atoi(m[2])
+		start := time.Date(y, time.Month(3*q-2), 1, 0, 0, 0, 0, loc)
+		return Period{start, start.AddDate(0, 3, 0)}, nil
+	}
+	switch strings.ToLower(s) {
+	case "today":
+		return Period{today, today.AddDate(0, 0, 1)}, nil
+	case "yesterday":
+		return Period{today.AddDate(0, 0, -1), today}, nil
+	case "this-week":
+		return Period{week, week.AddDate(0, 0, 7)}, nil
+	case "last-week":
+		return Period{week.AddDate(0, 0, -7), week}, nil
+	case "this-month":
+		return Period{month, month.AddDate(0, 1, 0)}, nil
+	case "last-month":
+		return Period{month.AddDate(0, -1, 0), month}, nil
+	case "this-quarter":
+		return Period{quarter, quarter.AddDate(0, 3, 0)}, nil
+	case "last-quarter":
+		return Period{quarter.AddDate(0, -3, 0), quarter}, nil
+	case "this-year":
+		return Period{year, year.AddDate(1, 0, 0)}, nil
+	case "last-year":
+		return Period{year.AddDate(-1, 0, 0), year}, nil
+	}
+	for _, f := range []struct {
+		layout string
+		years  int
+		months int
+		days   int
+	}{
+		{"2006-01-02", 0, 0, 1},
+		{"2006-01", 0, 1, 0},
+		{"2006", 1, 0, 0},
+	} {
+		if t, err := time.ParseInLocation(f.layout, s, loc); err == nil {
+			return Period{t, t.AddDate(f.years, f.months, f.days)}, nil
+		}
+	}
+	return Period{}, fmt.Errorf("%q is not a date or period, e.g. 2026-07-01, 2026-Q3, 90d or last-quarter", s)
+}
+
+// within reports whether t falls between opts.Since and opts.Until. A zero
+// t, a date something does not have, only falls within an open range.
+func (o Options) within(t time.Time) bool {
+	if t.IsZero() {
+		return o.Since.IsZero() && o.Until.IsZero()
+	}
+	if !o.Since.IsZero() && t.Before(o.Since) {
+		return false
+	}
+	return o.Until.IsZero() || t.Before(o.Until)
+}
+
+// prDate returns the date of pr opts.DateField selects, zero when the PR
+// has none, such as the merge date of an open PR. Such PRs are left out
+// of date ranges, but still counted without one.
+func (o Options) prDate(pr *github.PullRequest) time.Time {
+	switch o.DateField {
+	case DateMerged:
+		return pr.GetMergedAt()
+	case DateClosed:
+		return pr.GetClosedAt()
+	}
+	return pr.GetCreatedAt()
+}
+
+// clipWeeks returns the weeks of contributor statistics overlapping the
+// time between opts.Since and opts.Until.
+func (o Options) clipWeeks(weeks []github.WeeklyStats) []github.WeeklyStats {
+	if o.Since.IsZero() && o.Until.IsZero() {
+		return weeks
+	}
+	var clipped []github.WeeklyStats
+	for _, wk := range weeks {
+		start := wk.Week.Time
+		if (o.Since.IsZero() || start.AddDate(0, 0, 7).After(o.Since)) && (o.Until.IsZero() || start.Before(o.Until)) {
+			clipped = append(clipped, wk)
+		}
+	}
+	return clipped
+}
@@ -0,0 +1,144 @@
+package scrape
+
+import (
+	"testing"
+	"time"
+)
+
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParsePeriod(t *testing.T) {
+	// a Monday
+	now := time.Date(2026, 10, 19, 15, 4, 5, 0, time.UTC)
+	// a Sunday, and a Thursday starting a year and a quarter
+	sunday := time.Date(2026, 10, 25, 23, 0, 0, 0, time.UTC)
+	newYear := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		in    string
+		now   time.Time
+		start time.Time
+		end   time.Time
+	}{
+		{"2026-07-01", now, day(2026, 7, 1), day(2026, 7, 2)},
+		{"2026-02-28", now, day(2026, 2, 28), day(2026, 3, 1)},
+		{"2026-07", now, day(2026, 7, 1), day(2026, 8, 1)},
+		{"2026-12", now, day(2026, 12, 1), day(2027, 1, 1)},
+		{"2026-Q1", now, day(2026, 1, 1), day(2026, 4, 1)},
+		{"2026-Q3", now, day(2026, 7, 1), day(2026, 10, 1)},
+		{"2026-q4", now, day(2026, 10, 1), day(2027, 1, 1)},
+		{"2026", now, day(2026, 1, 1), day(2027, 1, 1)},
+		{"90d", now, now.AddDate(0, 0, -90), now},
+		{"12w", now, now.AddDate(0, 0, -84), now},
+		{"6m", now, time.Date(2026, 4, 19, 15, 4, 5, 0, time.UTC), now},
+		{"1y", now, time.Date(2025, 10, 19, 15, 4, 5, 0, time.UTC), now},
+		{"today", now, day(2026, 10, 19), day(2026, 10, 20)},
+		{"yesterday", now, day(2026, 10, 18), day(2026, 10, 19)},
+		{"yesterday", newYear, day(2025, 12, 31), day(2026, 1, 1)},
+		{"this-week", now, day(2026, 10, 19), day(2026, 10, 26)},
+		{"this-week", sunday, day(2026, 10, 19), day(2026, 10, 26)},
+		{"this-week", newYear, day(2025, 12, 29), day(2026, 1, 5)},
+		{"last-week", now, day(2026, 10, 12), day(2026, 10, 19)},
+		{"last-week", sunday, day(2026, 10, 12), day(2026, 10, 19)},
+		{"this-month", now, day(2026, 10, 1), day(2026, 11, 1)},
+		{"last-month", now, day(2026, 9, 1), day(2026, 10, 1)},
+		{"last-month", newYear, day(2025, 12, 1), day(2026, 1, 1)},
+		{"this-quarter", now, day(2026, 10, 1), day(2027, 1, 1)},
+		{"last-quarter", now, day(2026, 7, 1), day(2026, 10, 1)},
+		{"last-quarter", newYear, day(2025, 10, 1), day(2026, 1, 1)},
+		{"this-year", now, day(2026, 1, 1), day(2027, 1, 1)},
+		{"last-year", now, day(2025, 1, 1), day(2026, 1, 1)},
+		{"LAST-QUARTER", now, day(2026, 7, 1), day(2026, 10, 1)},
+	}
+	for _, tt := range tests {
+		p, err := ParsePeriod(tt.in, tt.now)
+		if err != nil {
+			t.Errorf("ParsePeriod(%q, %v): %v", tt.in, tt.now, err)
+			continue
+		}
+		if !p.Start.Equal(tt.start) || !p.End.Equal(tt.end) {
+			t.Errorf("ParsePeriod(%q, %v) = %v - %v, want %v - %v", tt.in, tt.now, p.Start, p.End, tt.start, tt.end)
+		}
+	}
+}
+
+func TestParsePeriodErrors(t *testing.T) {
+	now := time.Date(2026, 10, 19, 15, 4, 5, 0, time.UTC)
+	for _, in := range []string{"", "soon", "2026-Q5", "2026-Q0", "2026-13", "2026-02-30", "d90", "-90d", "26"} {
+		if p, err := ParsePeriod(in, now); err == nil {
+			t.Errorf("ParsePeriod(%q) = %v, want an error", in, p)
+		}
+	}
+}
+
+func TestPeriodString(t *testing.T) {
+	p := Period{day(2026, 7, 1), day(2026, 10, 1)}
+	if got, want := p.String(), "2026-07-01 to 2026-09-30"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestWithin(t *testing.T) {
+	since, until := day(2026, 7, 1), day(2026, 10, 1)
+	tests := []struct {
+		since, until time.Time
+		t            time.Time
+		want         bool
+	}{
+		{time.Time{}, time.Time{}, day(2026, 1, 1), true},
+		{time.Time{}, time.Time{}, time.Time{}, true},
+		{since, time.Time{}, day(2026, 6, 30), false},
+		{since, time.Time{}, since, true},
+		{time.Time{}, until, until.Add(-time.Nanosecond), true},
+		{time.Time{}, until, until, false},
+		{since, until, day(2026, 8, 15), true},
+		{since, until, time.Time{}, false},
+		{time.Time{}, until, time.Time{}, false},
+	}
+	for _, tt := range tests {
+		o := Options{Since: tt.since, Until: tt.until}
+		if got := o.within(tt.t); got != tt.want {
+			t.Errorf("within(%v) since %v until %v = %v, want %v", tt.t, tt.since, tt.until, got, tt.want)
+		}
+	}
+}
+
+func TestPeriodPrevious(t *testing.T) {
+	now := time.Date(2026, 10, 19, 15, 4, 5, 0, time.UTC)
+	tests := []struct {
+		in    string
+		start time.Time
+		end   time.Time
+	}{
+		{"2026-Q3", day(2026, 4, 1), day(2026, 7, 1)},
+		{"2026-Q1", day(2025, 10, 1), day(2026, 1, 1)},
+		{"2026-03", day(2026, 2, 1), day(2026, 3, 1)},
+		{"2026-05", day(2026, 4, 1), day(2026, 5, 1)},
+		{"2026", day(2025, 1, 1), day(2026, 1, 1)},
+		{"2026-03-01", day(2026, 2, 28), day(2026, 3, 1)},
+		{"this-week", day(2026, 10, 12), day(2026, 10, 19)},
+		{"90d", now.AddDate(0, 0, -90).Add(-90 * 24 * time.Hour), now.AddDate(0, 0, -90)},
+	}
+	for _, tt := range tests {
+		p, err := ParsePeriod(tt.in, now)
+		if err != nil {
+			t.Fatalf("ParsePeriod(%q): %v", tt.in, err)
+		}
+		prev := p.Previous()
+		if !prev.Start.Equal(tt.start) || !prev.End.Equal(tt.end) {
+			t.Errorf("Previous of %q = %v, want %v", tt.in, prev, Period{tt.start, tt.end})
+		}
+	}
+
+	// whole days step back by calendar days across daylight saving time
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+	p := Period{time.Date(2026, 3, 23, 0, 0, 0, 0, loc), time.Date(2026, 3, 30, 0, 0, 0, 0, loc)}
+	if prev, want := p.Previous(), time.Date(2026, 3, 16, 0, 0, 0, 0, loc); !prev.Start.Equal(want) {
+		t.Errorf("week before %v starts %v, want %v", p, prev.Start, want)
+	}
+}
@@ -0,0 +1,85 @@
+package scrape
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"unicode/utf8"
+)
+
+// privacy modes for Options.Privacy
+const (
+	PrivacyOmit = "omit"
+	PrivacyMask = "mask"
+	PrivacyHash = "hash"
+)
+
+// redactEmail applies the privacy mode of o to e.
+func (o Options) redactEmail(e string) string {
+	switch o.Privacy {
+	case PrivacyOmit:
+		return ""
+	case PrivacyMask:
+		i := strings.LastIndex(e, "@")
+		if i < 1 {
+			return "***"
+		}
+		r, _ := utf8.DecodeRuneInString(e)
+		return string(r) + "***" + e[i:]
+	case PrivacyHash:
+		sum := sha256.Sum256([]byte(o.Salt + strings.ToLower(e)))
+		return "sha256:" + hex.EncodeToString(sum[:])[:16]
+	}
+	return e
+}
+
+// gitName returns the name shown for a git identity without a GitHub
+// account: its name, or the local part of its email when it has none, so
+// that addresses never end up in logins. With o.Privacy the local part is
+// masked, or replaced with the hash of the email.
+func (o Options) gitName(name, e string) string {
+	if name != "" {
+		return name
+	}
+	if o.Privacy == PrivacyHash {
+		return o.redactEmail(e)
+	}
+	if i := strings.LastIndex(e, "@"); i >= 0 {
+		e = e[:i]
+	}
+	if e == "" {
+		return "unknown"
+	}
+	if o.Privacy != "" {
+		r, _ := utf8.DecodeRuneInString(e)
+		return string(r) + "***"
+	}
+	return e
+}
+
+// redact returns a copy of b with the emails of every stat and item
+// redacted according to o.Privacy. The names of authors without a GitHub
+// account are redacted when they are collected, see gitName.
+func (o Options) redact(b byCount) byCount {
+	if o.Privacy == "" {
+		return b
+	}
+	out := make(byCount, len(b))
+	for n, s := range b {
+		emails := []string{}
+		if o.Privacy != PrivacyOmit {
+			for _, e := range s.Email {
+				emails = append(emails, o.redactEmail(e))
+			}
+		}
+		s.Email = emails
+		items := make([]item, len(s.Items))
+		for i, it := range s.Items {
+			it.Email = o.redactEmail(it.Email)
+			items[i] = it
+		}
+		s.Items = items
+		out[n] = s
+	}
+	return out
+}
@@ -0,0 +1,100 @@
+package scrape
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRedactEmail(t *testing.T) {
+	tests := []struct {
+		privacy, email, want string
+	}{
+		{"", "jane@example.com", "jane@example.com"},
+		{PrivacyOmit, "jane@example.com", ""},
+		{PrivacyMask, "jane@example.com", "j***@example.com"},
+		{PrivacyMask, "élise@example.com", "é***@example.com"},
+		{PrivacyMask, "jane.doe@mail@example.com", "j***@example.com"},
+		{PrivacyMask, "@example.com", "***"},
+		{PrivacyMask, "jane", "***"},
+		{PrivacyMask, "", "***"},
+	}
+	for _, tt := range tests {
+		if got := (Options{Privacy: tt.privacy}).redactEmail(tt.email); got != tt.want {
+			t.Errorf("redactEmail(%q) with %q = %q, want %q", tt.email, tt.privacy, got, tt.want)
+		}
+	}
+
+	o := Options{Privacy: PrivacyHash, Salt: "salt"}
+	h := o.redactEmail("jane@example.com")
+	if !strings.HasPrefix(h, "sha256:") || len(h) != len("sha256:")+16 || strings.Contains(h, "jane") {
+		t.Errorf("redactEmail hashed to %q", h)
+	}
+	if o.redactEmail("Jane@Example.com") != h {
+		t.Error("hashes should ignore the case of emails")
+	}
+	if (Options{Privacy: PrivacyHash, Salt: "pepper"}).redactEmail("jane@example.com") == h {
+		t.Error("hashes should depend on the salt")
+	}
+}
+
+func TestGitName(t *testing.T) {
+	tests := []struct {
+		privacy, name, email, want string
+	}{
+		{"", "Jane Doe", "jane@example.com", "Jane Doe"},
+		{"", "", "john.smith@corp.com", "john.smith"},
+		{"", "", "", "unknown"},
+		{"", "", "@corp.com", "unknown"},
+		{PrivacyMask, "Jane Doe", "jane@example.com", "Jane Doe"},
+		{PrivacyMask, "", "john.smith@corp.com", "j***"},
+		{PrivacyOmit, "", "john.smith@corp.com", "j***"},
+		{PrivacyMask, "", "élise@corp.com", "é***"},
+		{PrivacyMask, "", "", "unknown"},
+	}
+	for _, tt := range tests {
+		if got := (Options{Privacy: tt.privacy}).gitName(tt.name, tt.email); got != tt.want {
+			t.Errorf("gitName(%q, %q) with %q = %q, want %q", tt.name, tt.email, tt.privacy, got, tt.want)
+		}
+	}
+
+	o := Options{Privacy: PrivacyHash, Salt: "salt"}
+	if got, want := o.gitName("", "john.smith@corp.com"), o.redactEmail("john.smith@corp.com"); got != want {
+		t.Errorf("gitName with hash = %q, want the hash of the email %q", got, want)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	b := byCount{
+		{Login: "jdoe", Email: []string{"jane@example.com", "jd@example.org"}, Items: []item{{Ref: "abc", Email: "jane@example.com"}}},
+		{Login: "j***", Unlinked: true, Email: []string{"john.smith@corp.com"}, Items: []item{{Ref: "def", Email: "john.smith@corp.com"}}},
+	}
+	tests := []struct {
+		privacy string
+		emails  [][]string
+		items   []string
+	}{
+		{PrivacyOmit, [][]string{{}, {}}, []string{"", ""}},
+		{PrivacyMask, [][]string{{"j***@example.com", "j***@example.org"}, {"j***@corp.com"}}, []string{"j***@example.com", "j***@corp.com"}},
+	}
+	for _, tt := range tests {
+		got := Options{Privacy: tt.privacy}.redact(b)
+		for n, s := range got {
+			if !reflect.DeepEqual(s.Email, tt.emails[n]) {
+				t.Errorf("%s: emails of %s = %q, want %q", tt.privacy, s.Login, s.Email, tt.emails[n])
+			}
+			if s.Items[0].Email != tt.items[n] {
+				t.Errorf("%s: item email of %s = %q, want %q", tt.privacy, s.Login, s.Items[0].Email, tt.items[n])
+			}
+			if s.Login != b[n].Login || s.Items[0].Ref != b[n].Items[0].Ref {
+				t.Errorf("%s: redact changed %+v to %+v", tt.privacy, b[n], s)
+			}
+		}
+	}
+	if b[0].Email[0] != "jane@example.com" || b[0].Items[0].Email != "jane@example.com" {
+		t.Error("redact should not modify its input")
+	}
+	if got := (Options{}).redact(b); !reflect.DeepEqual(got, b) {
+		t.Error("redact without privacy should return b unchanged")
+	}
+}
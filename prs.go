@@ -3,20 +3,27 @@ package scrape
 import (
 	"context"
 	"fmt"
-	"log"
 	"os"
-	"sort"
 	"text/tabwriter"
 	"time"
 
 	"github.com/google/go-github/github"
 )
 
-// GetPRs prints to stdout a sorted list of either closed or open PRs to
-// specified organization's repository
-func GetPRs(client *github.Client, org, repo, state string) {
+// prStats fetches the PRs of a repository in the given state and returns
+// them aggregated per author, ranked and sorted by ascending count. Besides
+// the states understood by GitHub, state may be "merged" to only count
+// closed PRs that were merged. Only the PRs whose opts.DateField falls
+// between opts.Since and opts.Until are counted.
+func prStats(client *github.Client, org, repo, state string, opts Options) (byCount, error) {
+	merged := state == "merged"
+	if merged {
+		state = "closed"
+	}
 	opt := &github.PullRequestListOptions{
-		State: state,
+		State:     state,
+		Sort:      "created",
+		Direction: "desc",
 		ListOptions: github.ListOptions{
 			PerPage: 100,
 		},
@@ -27,51 +34,113 @@ func GetPRs(client *github.Client, org, repo, state string) {
 		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 		defer cancel()
 		prs, resp, err := client.PullRequests.List(ctx, org, repo, opt)
-		if _, ok := err.(*github.RateLimitError); ok {
-			log.Println("hit rate limit")
-			return
-		}
 		if err != nil {
-			log.Fatal(err)
+			return nil, err
 		}
 		for _, pr := range prs {
-			a := "username missing"
-			if pr.User != nil {
-				a = *pr.User.Login
+			if merged && pr.MergedAt == nil {
+				continue
 			}
-			_, ok := m[a]
-			if !ok {
-				m[a] = &stat{Login: a, Email: []string{}, Count: 1}
+			d := opts.prDate(pr)
+			if !opts.within(d) {
 				continue
 			}
-			tmp := m[a]
+			if d.IsZero() {
+				d = pr.GetCreatedAt()
+			}
+			k := accountKey(pr.User)
+			it := item{Date: d, Ref: fmt.Sprintf("#%d", pr.GetNumber()), Title: pr.GetTitle()}
+			tmp, ok := m[k]
+			if !ok {
+				tmp = opts.accountStat(pr.User)
+				m[k] = tmp
+			}
 			tmp.Count += 1
+			tmp.Dates = append(tmp.Dates, d)
+			tmp.Items = append(tmp.Items, it)
 		}
 		if resp.NextPage == 0 {
 			break
 		}
+		// PRs are listed newest first, so once a page ends before
+		// opts.Since the rest were all created before it
+		if opts.DateField != DateMerged && opts.DateField != DateClosed && len(prs) > 0 && prs[len(prs)-1].GetCreatedAt().Before(opts.Since) {
+			break
+		}
 		opt.ListOptions.Page = resp.NextPage
 	}
 
-	b := byCount{}
-	for _, v := range m {
-		b = append(b, *v)
+	b := ranked(m)
+	opts.trackLogins(b)
+	return b, nil
+}
+
+// GetPRs prints to stdout a sorted list of either closed or open PRs to
+// specified organization's repository
+func GetPRs(client *github.Client, org, repo, state string, opts Options) {
+	b, err := prStats(client, org, repo, state, opts)
+	if err != nil {
+		reportErr(err)
+		return
+	}
+	printPRs(client, b, opts)
+}
+
+// printPRs prints the PR leaderboard b to stdout.
+func printPRs(client *github.Client, b byCount, opts Options) {
+	b, bots := opts.filterBots(b)
+	if opts.By != "" {
+		g, err := byGroup(client, b, opts)
+		if err != nil {
+			reportErr(err)
+			return
+		}
+		if opts.Bucket != "" {
+			printBuckets(g, "PRs", opts)
+			return
+		}
+		printGroups(g, "PRs", opts)
+		return
+	}
+	if opts.Bucket != "" {
+		printBuckets(b, "PRs", opts)
+		return
+	}
+
+	b, err := enrich(client, b, opts)
+	if err != nil {
+		reportErr(err)
+		return
 	}
-	sort.Sort(b)
 
 	w := new(tabwriter.Writer)
 	w.Init(os.Stdout, 10, 8, 0, '\t', 0)
-	fmt.Fprintln(w, "rank\tlogin\tPRs")
+	header := "rank\tlogin\tPRs"
+	if opts.Enrich {
+		header += profileHeader
+	}
+	var ch *charts
+	if opts.Charts {
+		ch = newCharts(b)
+		header += ch.header()
+	}
+	fmt.Fprintln(w, header)
 
 	total := 0
-	atotal := len(m)
-	for n, v := range b {
+	for _, v := range b {
 		total += v.Count
-		v.Rank = atotal - n
-		fmt.Fprintln(w, fmt.Sprintf("%d\t%s\t%d", v.Rank, v.Login, v.Count))
+		row := fmt.Sprintf("%d\t%s\t%d", v.Rank, v.who(), v.Count)
+		if opts.Enrich {
+			row += profileColumns(v)
+		}
+		if ch != nil {
+			row += ch.columns(v)
+		}
+		fmt.Fprintln(w, row)
 	}
 	fmt.Fprintln(w)
 	w.Flush()
 	fmt.Printf("TOTAL PRs: %d\n", total)
-	fmt.Printf("TOTAL AUTHORS: %d\n", atotal)
+	fmt.Printf("TOTAL AUTHORS: %d\n", len(b))
+	opts.printBots(bots, "PRs")
 }
@@ -0,0 +1,110 @@
+package scrape
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// ResolveRepos expands patterns into the repositories they name. A pattern
+// is an owner/repo, an owner/glob such as kubernetes/*-controller matched
+// against the repositories of the owner, or a bare owner standing for all
+// its repositories. f applies to the repositories of globs and bare
+// owners. Repositories named more than once are returned once.
+func ResolveRepos(client *github.Client, patterns []string, f RepoFilter) ([]Repo, error) {
+	var repos []Repo
+	seen := make(map[Repo]bool)
+	add := func(r Repo) {
+		if !seen[r] {
+			seen[r] = true
+			repos = append(repos, r)
+		}
+	}
+	listed := make(map[string][]Repo)
+	for _, p := range patterns {
+		parts := strings.Split(p, "/")
+		if len(parts) > 2 || parts[0] == "" || len(parts) == 2 && parts[1] == "" {
+			return nil, fmt.Errorf("poorly formated repository %q", p)
+		}
+		if len(parts) == 2 && !strings.ContainsAny(parts[1], "*?[") {
+			add(Repo{Owner: parts[0], Name: parts[1]})
+			continue
+		}
+		all, ok := listed[parts[0]]
+		if !ok {
+			var err error
+			if all, err = ListOrgRepos(client, parts[0], f); err != nil {
+				return nil, err
+			}
+			listed[parts[0]] = all
+		}
+		for _, r := range all {
+			if len(parts) == 1 {
+				add(r)
+				continue
+			}
+			ok, err := path.Match(parts[1], r.Name)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", p, err)
+			}
+			if ok {
+				add(r)
+			}
+		}
+	}
+	return repos, nil
+}
+
+// LoadRepoList reads the repository patterns listed in the file at path,
+// one per line. Blank lines and lines starting with # are skipped.
+func LoadRepoList(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var patterns []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns, s.Err()
+}
+
+// SearchRepos returns the repositories matching a repository search
+// query, such as "topic:foo org:bar". The search API returns at most 1000
+// of them.
+func SearchRepos(client *github.Client, query string) ([]Repo, error) {
+	opt := &github.SearchOptions{
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+	var repos []Repo
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		res, resp, err := client.Search.Repositories(ctx, query, opt)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range res.Repositories {
+			repos = append(repos, Repo{Owner: r.Owner.GetLogin(), Name: r.GetName()})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.ListOptions.Page = resp.NextPage
+	}
+	return repos, nil
+}
@@ -0,0 +1,141 @@
+package scrape
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// reviewStats fetches the reviews of every PR of a repository and returns
+// them aggregated per reviewer, ranked and sorted by ascending count. A
+// reviewer counts once per PR however many reviews they submitted, and
+// authors replying on their own PR are not counted. Only the reviews
+// submitted between opts.Since and opts.Until are counted.
+func reviewStats(client *github.Client, org, repo string, opts Options) (byCount, error) {
+	opt := &github.PullRequestListOptions{
+		State: "all",
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+
+	m := make(map[string]*stat)
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		prs, resp, err := client.PullRequests.List(ctx, org, repo, opt)
+		if err != nil {
+			return nil, err
+		}
+		for _, pr := range prs {
+			if !opts.Since.IsZero() && pr.GetUpdatedAt().Before(opts.Since) {
+				continue
+			}
+			reviewed := map[string]bool{accountKey(pr.User): true}
+			ropt := &github.ListOptions{PerPage: 100}
+			for {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+				reviews, resp, err := client.PullRequests.ListReviews(ctx, org, repo, pr.GetNumber(), ropt)
+				if err != nil {
+					return nil, err
+				}
+				for _, r := range reviews {
+					k := accountKey(r.User)
+					if reviewed[k] || r.GetState() == "PENDING" || !opts.within(r.GetSubmittedAt()) {
+						continue
+					}
+					reviewed[k] = true
+					d := r.GetSubmittedAt()
+					it := item{Date: d, Ref: fmt.Sprintf("#%d", pr.GetNumber()), Title: pr.GetTitle()}
+					tmp, ok := m[k]
+					if !ok {
+						tmp = opts.accountStat(r.User)
+						m[k] = tmp
+					}
+					tmp.Count += 1
+					tmp.Dates = append(tmp.Dates, d)
+					tmp.Items = append(tmp.Items, it)
+				}
+				if resp.NextPage == 0 {
+					break
+				}
+				ropt.Page = resp.NextPage
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.ListOptions.Page = resp.NextPage
+	}
+
+	b := ranked(m)
+	opts.trackLogins(b)
+	return b, nil
+}
+
+// GetReviews prints to stdout a sorted list of the reviewers of the PRs to
+// specified organization's repository
+func GetReviews(client *github.Client, org, repo string, opts Options) {
+	b, err := reviewStats(client, org, repo, opts)
+	if err != nil {
+		reportErr(err)
+		return
+	}
+	b, bots := opts.filterBots(b)
+	if opts.By != "" {
+		g, err := byGroup(client, b, opts)
+		if err != nil {
+			reportErr(err)
+			return
+		}
+		if opts.Bucket != "" {
+			printBuckets(g, "reviews", opts)
+			return
+		}
+		printGroups(g, "reviews", opts)
+		return
+	}
+	if opts.Bucket != "" {
+		printBuckets(b, "reviews", opts)
+		return
+	}
+
+	b, err = enrich(client, b, opts)
+	if err != nil {
+		reportErr(err)
+		return
+	}
+
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 10, 8, 0, '\t', 0)
+	header := "rank\tlogin\treviewed PRs"
+	if opts.Enrich {
+		header += profileHeader
+	}
+	var ch *charts
+	if opts.Charts {
+		ch = newCharts(b)
+		header += ch.header()
+	}
+	fmt.Fprintln(w, header)
+	for _, v := range b {
+		row := fmt.Sprintf("%d\t%s\t%d", v.Rank, v.who(), v.Count)
+		if opts.Enrich {
+			row += profileColumns(v)
+		}
+		if ch != nil {
+			row += ch.columns(v)
+		}
+		fmt.Fprintln(w, row)
+	}
+	fmt.Fprintln(w)
+	w.Flush()
+	fmt.Printf("TOTAL REVIEWS: %d\n", b.total())
+	fmt.Printf("TOTAL REVIEWERS: %d\n", len(b))
+	opts.printBots(bots, "reviews")
+}
@@ -1,24 +1,90 @@
 package scrape
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
 
 type stat struct {
 	Login string   `json:"login"`
 	Email []string `json:"email"`
 	Count int      `json:"count"`
 	Rank  int      `json:"rank"`
+	// ID is the GitHub user ID of the contributor, which stays the same
+	// when the account is renamed. PreviousLogins lists the other logins
+	// it was seen with by earlier runs, see Options.trackLogins.
+	ID             int      `json:"id,omitempty"`
+	PreviousLogins []string `json:"previous_logins,omitempty"`
+	// Deleted is set for the contributions of deleted accounts, which
+	// GitHub credits to the ghost user.
+	Deleted bool `json:"deleted,omitempty"`
+	// Unlinked is set for commit authors without a GitHub account, whose
+	// Login then holds their git author name.
+	Unlinked bool `json:"unlinked,omitempty"`
+	// Bot is set for accounts detected as bots, see Options.isBot.
+	Bot bool `json:"bot,omitempty"`
+	// CoAuthored counts the commits crediting the contributor in a
+	// trailer, each adding Options.CoAuthorWeight to Credit.
+	CoAuthored int     `json:"co_authored,omitempty"`
+	Credit     float64 `json:"credit,omitempty"`
+	// Members lists the contributors rolled up into a group, such as a
+	// company.
+	Members []string `json:"members,omitempty"`
+	// Profile is the GitHub profile of the contributor, see
+	// Options.Enrich.
+	Profile *profile    `json:"profile,omitempty"`
+	Dates   []time.Time `json:"-"`
+	Items   []item      `json:"-"`
+	// Weights, when set, holds the share of each of Dates the stat is
+	// credited with, such as a contribution split between teams.
+	Weights []float64 `json:"-"`
+}
+
+// item is a single contribution (a commit, a PR, ...) behind a stat.
+type item struct {
+	Date  time.Time
+	Ref   string
+	Title string
+	// Email is the canonical author email of a commit, and SHA its full
+	// hash.
+	Email string
+	SHA   string
+	// Author is who wrote a commit counted for its committer, when that
+	// is someone else.
+	Author string
+	// Repo is the owner/name of the repository of the item, set when
+	// aggregating several repositories.
+	Repo string
 }
 
 type byCount []stat
 
+// who returns the name to display for s. Authors without a GitHub account
+// are shown in parentheses so they are not mistaken for logins, and renamed
+// accounts with the logins they had before.
+func (s stat) who() string {
+	switch {
+	case s.Unlinked:
+		return "(" + s.Login + ")"
+	case s.Deleted:
+		return s.Login + " (deleted user)"
+	case len(s.PreviousLogins) > 0:
+		return s.Login + " (formerly " + strings.Join(s.PreviousLogins, ", ") + ")"
+	}
+	return s.Login
+}
+
 func (s stat) String() string {
 	if len(s.Email) < 3 {
-		return fmt.Sprintf("%d\t%s\t%v\t%d", s.Rank, s.Login, s.Email, s.Count)
+		return fmt.Sprintf("%d\t%s\t%v\t%d", s.Rank, s.who(), s.Email, s.Count)
 	}
 	return fmt.Sprintf(
 		"%d\t%s\t%v\t%d",
 		s.Rank,
-		s.Login,
+		s.who(),
 		fmt.Sprintf("[%s [...] %s]", s.Email[0], s.Email[len(s.Email)-1]),
 		s.Count,
 	)
@@ -33,5 +99,143 @@ func (s byCount) Swap(i, j int) {
 }
 
 func (s byCount) Less(i, j int) bool {
-	return s[i].Count < s[j].Count
+	return s[i].score() < s[j].score()
+}
+
+// weight returns the share of the i-th of s.Dates credited to s.
+func (s stat) weight(i int) float64 {
+	if i < len(s.Weights) {
+		return s.Weights[i]
+	}
+	return 1
+}
+
+// score is what stats are ranked by: their count plus any co-author
+// credit.
+func (s stat) score() float64 {
+	return float64(s.Count) + s.Credit
+}
+
+// ranked flattens m into a byCount sorted by ascending count, ranking the
+// largest count first.
+func ranked(m map[string]*stat) byCount {
+	b := byCount{}
+	for _, v := range m {
+		b = append(b, *v)
+	}
+	sort.Sort(b)
+	for n := range b {
+		b[n].Rank = len(b) - n
+	}
+	return b
+}
+
+// total returns the sum of the counts in s.
+func (s byCount) total() int {
+	t := 0
+	for _, v := range s {
+		t += v.Count
+	}
+	return t
+}
+
+// coAuthored returns the number of co-author credits in s.
+func (s byCount) coAuthored() int {
+	n := 0
+	for _, v := range s {
+		n += v.CoAuthored
+	}
+	return n
+}
+
+// hasEmails reports whether any of the contributions behind s carries the
+// email of its author.
+func (s byCount) hasEmails() bool {
+	for _, v := range s {
+		for _, it := range v.Items {
+			if it.Email != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// unlinked returns the number of stats without a GitHub account.
+func (s byCount) unlinked() int {
+	n := 0
+	for _, v := range s {
+		if v.Unlinked {
+			n++
+		}
+	}
+	return n
+}
+
+// mergeByEmail merges the stats in b that share an email address into a
+// single identity, named after the login with the largest count. Logins
+// win over authors without a GitHub account.
+func mergeByEmail(b byCount) byCount {
+	parent := make([]int, len(b))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	owner := make(map[string]int)
+	for i, s := range b {
+		for _, e := range s.Email {
+			if e == "fake@fake.com" {
+				continue
+			}
+			if j, ok := owner[e]; ok {
+				parent[find(i)] = find(j)
+				continue
+			}
+			owner[e] = i
+		}
+	}
+
+	groups := make(map[int][]int)
+	for i := range b {
+		r := find(i)
+		groups[r] = append(groups[r], i)
+	}
+	m := make(map[string]*stat)
+	for _, g := range groups {
+		lead := g[0]
+		for _, i := range g {
+			if b[lead].Unlinked && !b[i].Unlinked || b[i].Unlinked == b[lead].Unlinked && b[i].Count > b[lead].Count {
+				lead = i
+			}
+		}
+		s := &stat{
+			Login:          b[lead].Login,
+			ID:             b[lead].ID,
+			PreviousLogins: b[lead].PreviousLogins,
+			Unlinked:       b[lead].Unlinked,
+			Deleted:        b[lead].Deleted,
+			Bot:            b[lead].Bot,
+			Email:          []string{},
+		}
+		for _, i := range g {
+			s.Count += b[i].Count
+			s.CoAuthored += b[i].CoAuthored
+			s.Credit += b[i].Credit
+			s.Dates = append(s.Dates, b[i].Dates...)
+			s.Items = append(s.Items, b[i].Items...)
+			for _, e := range b[i].Email {
+				if !contains(e, s.Email) {
+					s.Email = append(s.Email, e)
+				}
+			}
+		}
+		m[strconv.Itoa(find(g[0]))] = s
+	}
+	return ranked(m)
 }
@@ -0,0 +1,188 @@
+package scrape
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// Policies for contributors in several teams, see Options.TeamPolicy.
+const (
+	// TeamSplit divides the contributions evenly between the teams.
+	TeamSplit = "split"
+	// TeamDuplicate counts the contributions fully for every team.
+	TeamDuplicate = "duplicate"
+	// TeamPrimary counts the contributions for the most specific team
+	// only: the most deeply nested one, then the smallest.
+	TeamPrimary = "primary"
+)
+
+// noTeam is the group of contributors in none of the teams.
+const noTeam = "no team"
+
+// Teams maps logins to the teams of an organization they belong to.
+type Teams struct {
+	// byLogin lists the teams of every login, most specific first.
+	byLogin map[string][]string
+}
+
+// orgTeam is the part of a team LoadTeams looks at. The vendored
+// github.Team has no Parent field.
+type orgTeam struct {
+	ID     int    `json:"id"`
+	Slug   string `json:"slug"`
+	Parent *struct {
+		ID int `json:"id"`
+	} `json:"parent"`
+}
+
+// LoadTeams fetches the teams of org and their members. Nested teams are
+// resolved, the members of a team counting as members of all the teams
+// above it as well.
+func LoadTeams(client *github.Client, org string) (*Teams, error) {
+	var teams []orgTeam
+	for page := 1; page != 0; {
+		req, err := client.NewRequest("GET", fmt.Sprintf("orgs/%s/teams?per_page=100&page=%d", org, page), nil)
+		if err != nil {
+			return nil, err
+		}
+		// nested teams are still a preview of the API
+		req.Header.Set("Accept", "application/vnd.github.hellcat-preview+json")
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		var list []orgTeam
+		resp, err := client.Do(ctx, req, &list)
+		if err != nil {
+			return nil, err
+		}
+		teams = append(teams, list...)
+		page = resp.NextPage
+	}
+
+	parent := make(map[int]int)
+	slug := make(map[int]string)
+	for _, t := range teams {
+		slug[t.ID] = t.Slug
+		if t.Parent != nil {
+			parent[t.ID] = t.Parent.ID
+		}
+	}
+	depth := func(id int) int {
+		d := 0
+		for id = parent[id]; id != 0 && d < len(teams); id = parent[id] {
+			d++
+		}
+		return d
+	}
+
+	members := make(map[string]map[string]bool)
+	size := make(map[string]int)
+	for _, t := range teams {
+		opt := &github.OrganizationListTeamMembersOptions{
+			ListOptions: github.ListOptions{
+				PerPage: 100,
+			},
+		}
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			users, resp, err := client.Organizations.ListTeamMembers(ctx, t.ID, opt)
+			if err != nil {
+				return nil, err
+			}
+			for _, u := range users {
+				// a member of a team is a member of its parents too
+				for id := t.ID; id != 0; id = parent[id] {
+					if members[u.GetLogin()] == nil {
+						members[u.GetLogin()] = make(map[string]bool)
+					}
+					if !members[u.GetLogin()][slug[id]] {
+						members[u.GetLogin()][slug[id]] = true
+						size[slug[id]]++
+					}
+				}
+			}
+			if resp.NextPage == 0 {
+				break
+			}
+			opt.ListOptions.Page = resp.NextPage
+		}
+	}
+
+	depthOf := make(map[string]int)
+	for _, t := range teams {
+		depthOf[t.Slug] = depth(t.ID)
+	}
+	m := &Teams{byLogin: make(map[string][]string)}
+	for login, set := range members {
+		var l []string
+		for s := range set {
+			l = append(l, s)
+		}
+		sort.Slice(l, func(i, j int) bool {
+			switch {
+			case depthOf[l[i]] != depthOf[l[j]]:
+				return depthOf[l[i]] > depthOf[l[j]]
+			case size[l[i]] != size[l[j]]:
+				return size[l[i]] < size[l[j]]
+			}
+			return l[i] < l[j]
+		})
+		m.byLogin[login] = l
+	}
+	return m, nil
+}
+
+// Of returns the teams login belongs to, most specific first. A nil Teams
+// has no teams.
+func (t *Teams) Of(login string) []string {
+	if t == nil {
+		return nil
+	}
+	return t.byLogin[login]
+}
+
+// byTeam rolls b up per team using opts.Teams, contributors in several
+// teams being counted following opts.TeamPolicy. With TeamSplit the
+// shares of a contribution are kept as credit rather than count, and as
+// the weight of its date.
+func byTeam(b byCount, opts Options) byCount {
+	m := make(map[string]*stat)
+	members := make(map[string]map[string]bool)
+	for _, s := range b {
+		teams := []string{noTeam}
+		if l := opts.Teams.Of(s.Login); !s.Unlinked && len(l) > 0 {
+			teams = l
+		}
+		if opts.TeamPolicy == TeamPrimary {
+			teams = teams[:1]
+		}
+		for _, g := range teams {
+			tmp, ok := m[g]
+			if !ok {
+				tmp = &stat{Login: g, Email: []string{}}
+				m[g] = tmp
+				members[g] = make(map[string]bool)
+			}
+			for _, it := range s.Items {
+				if opts.TeamPolicy == TeamSplit {
+					share := 1 / float64(len(teams))
+					tmp.Credit += share
+					tmp.Weights = append(tmp.Weights, share)
+				} else {
+					tmp.Count++
+				}
+				tmp.Dates = append(tmp.Dates, it.Date)
+				tmp.Items = append(tmp.Items, it)
+			}
+			if !members[g][s.who()] {
+				members[g][s.who()] = true
+				tmp.Members = append(tmp.Members, s.who())
+			}
+		}
+	}
+	return ranked(m)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sort"
 	"text/tabwriter"
 	"time"
 
@@ -12,8 +13,9 @@ import (
 )
 
 // Top100 prints to stdout the top100 contributors to organization's
-// repository
-func Top100(client *github.Client, org, repo string) {
+// repository. Their weekly statistics are clipped to the weeks between
+// opts.Since and opts.Until.
+func Top100(client *github.Client, org, repo string, opts Options) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
 	stats, _, err := client.Repositories.ListContributorsStats(ctx, org, repo)
@@ -23,14 +25,82 @@ func Top100(client *github.Client, org, repo string) {
 	if err != nil {
 		log.Fatal(err)
 	}
+	if !opts.Since.IsZero() || !opts.Until.IsZero() {
+		var clipped []*github.ContributorStats
+		for _, i := range stats {
+			total := 0
+			i.Weeks = opts.clipWeeks(i.Weeks)
+			for _, wk := range i.Weeks {
+				total += wk.GetCommits()
+			}
+			if total > 0 {
+				i.Total = &total
+				clipped = append(clipped, i)
+			}
+		}
+		sort.SliceStable(clipped, func(a, b int) bool { return clipped[a].GetTotal() < clipped[b].GetTotal() })
+		stats = clipped
+	}
+	var kept []*github.ContributorStats
+	var bots byCount
+	for _, i := range stats {
+		bot := opts.isBot(i.Author.GetLogin(), i.Author.GetType())
+		if bot {
+			bots = append(bots, stat{Login: i.Author.GetLogin(), Count: i.GetTotal(), Bot: true})
+		}
+		if bot && opts.ExcludeBots || !bot && opts.OnlyBots {
+			continue
+		}
+		kept = append(kept, i)
+	}
+	stats = kept
+
+	var profs map[string]*profile
+	if opts.Enrich {
+		var b byCount
+		for _, i := range stats {
+			b = append(b, stat{Login: i.Author.GetLogin()})
+		}
+		if profs, err = profiles(client, b, opts); err != nil {
+			reportErr(err)
+			return
+		}
+	}
+
 	w := new(tabwriter.Writer)
 	w.Init(os.Stdout, 10, 8, 0, '\t', 0)
-	fmt.Fprintln(w, "rank\tlogin\tcommits")
+	header := "rank\tlogin\tcommits"
+	if opts.Enrich {
+		header += profileHeader
+	}
+	var ch *charts
+	if opts.Charts {
+		ch = &charts{unicode: isTerminal(os.Stdout)}
+		for _, i := range stats {
+			if float64(*i.Total) > ch.max {
+				ch.max = float64(*i.Total)
+			}
+		}
+		header += ch.header()
+	}
+	fmt.Fprintln(w, header)
 
 	for n, i := range stats {
-		fmt.Fprintln(w, fmt.Sprintf("%d\t%s\t%d", (100-n), *i.Author.Login, *i.Total))
+		row := fmt.Sprintf("%d\t%s\t%d", len(stats)-n, *i.Author.Login, *i.Total)
+		if opts.Enrich {
+			row += profileColumns(stat{Profile: profs[i.Author.GetLogin()]})
+		}
+		if ch != nil {
+			weeks := make([]int, len(i.Weeks))
+			for j, wk := range i.Weeks {
+				weeks[j] = wk.GetCommits()
+			}
+			row += ch.series(weeks, float64(*i.Total))
+		}
+		fmt.Fprintln(w, row)
 	}
 	fmt.Fprintln(w)
 	w.Flush()
 	fmt.Printf("TOTAL TOP100 AUTHORS: %d\n", len(stats))
+	opts.printBots(bots, "commits")
 }
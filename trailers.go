@@ -0,0 +1,36 @@
+package scrape
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DefaultTrailers are the commit message trailers crediting co-authors
+// when Options.Trailers is empty.
+var DefaultTrailers = []string{"Co-authored-by"}
+
+var trailerRE = regexp.MustCompile(`^([A-Za-z-]+):\s*(.*?)\s*<([^>]+)>\s*$`)
+
+// trailer is a person credited by a commit message trailer.
+type trailer struct {
+	key, name, email string
+}
+
+// parseTrailers returns the trailers of msg whose key is one of keys,
+// ignoring case.
+func parseTrailers(msg string, keys []string) []trailer {
+	var out []trailer
+	for _, line := range strings.Split(msg, "\n") {
+		m := trailerRE.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		for _, k := range keys {
+			if strings.EqualFold(m[1], k) {
+				out = append(out, trailer{key: k, name: m[2], email: m[3]})
+				break
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,51 @@
+package scrape
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTrailers(t *testing.T) {
+	tests := []struct {
+		msg  string
+		keys []string
+		want []trailer
+	}{
+		{
+			"Fix the thing\n\nCo-authored-by: Jane Doe <jane@example.com>\nCo-authored-by: bob <bob@example.com>",
+			DefaultTrailers,
+			[]trailer{{"Co-authored-by", "Jane Doe", "jane@example.com"}, {"Co-authored-by", "bob", "bob@example.com"}},
+		},
+		{
+			// keys ignore case and surrounding space is trimmed
+			"Fix\n\n  co-authored-BY:   Jane Doe   <jane@example.com>  \r",
+			DefaultTrailers,
+			[]trailer{{"Co-authored-by", "Jane Doe", "jane@example.com"}},
+		},
+		{
+			// a trailer without a name
+			"Fix\n\nCo-authored-by: <bob@corp.com>",
+			DefaultTrailers,
+			[]trailer{{"Co-authored-by", "", "bob@corp.com"}},
+		},
+		{
+			// only the keys asked for
+			"Fix\n\nSigned-off-by: Jane Doe <jane@example.com>\nReviewed-by: Joe <joe@example.com>\nCo-authored-by: Ann <ann@example.com>",
+			[]string{"Signed-off-by", "Reviewed-by"},
+			[]trailer{{"Signed-off-by", "Jane Doe", "jane@example.com"}, {"Reviewed-by", "Joe", "joe@example.com"}},
+		},
+		{
+			// lines that are not trailers
+			"Co-authored-by Jane <jane@example.com>\nCo-authored-by: Jane\nCo-authored-by: Jane <jane@example.com> and more\nSee: http://example.com",
+			DefaultTrailers,
+			nil,
+		},
+		{"", DefaultTrailers, nil},
+		{"Fix\n\nCo-authored-by: Jane <jane@example.com>", nil, nil},
+	}
+	for _, tt := range tests {
+		if got := parseTrailers(tt.msg, tt.keys); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseTrailers(%q, %q) = %+v, want %+v", tt.msg, tt.keys, got, tt.want)
+		}
+	}
+}
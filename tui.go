@@ -0,0 +1,512 @@
+package scrape
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/go-github/github"
+)
+
+const (
+	quotaInterval = 15 * time.Second
+	tuiHelp       = "←→/tab switch  ↑↓ move  s sort  S reverse  / search  enter details  q quit"
+)
+
+// sort columns of the browser tables
+const (
+	colRank = iota
+	colLogin
+	colEmail
+	colCount
+	numCols
+)
+
+var colNames = [numCols]string{"rank", "login", "emails", "count"}
+
+// tab is one of the tables shown by the browser. Its rows are loaded the
+// first time the tab is shown.
+type tab struct {
+	name    string
+	unit    string
+	load    func() (byCount, error)
+	rows    byCount
+	err     error
+	loading bool
+	loaded  bool
+}
+
+// loadResult carries the rows of a tab back from its loading goroutine.
+type loadResult struct {
+	t    *tab
+	rows byCount
+	err  error
+}
+
+// browser holds the state of the interface started by Browse.
+type browser struct {
+	client    *github.Client
+	org, repo string
+	opts      Options
+	tabs      []*tab
+	cur       int
+	sel, top  int
+	sortCol   int
+	desc      bool
+	query     string
+	searching bool
+	detail    *stat
+	detailTop int
+	quota     string
+	width     int
+	height    int
+	results   chan loadResult
+}
+
+// Browse opens a full-screen interface on the terminal for exploring the
+// contributors of an organization's repository. It returns once the user
+// quits.
+func Browse(client *github.Client, org, repo string, opts Options) error {
+	if !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+		return fmt.Errorf("tui requires an interactive terminal")
+	}
+	saved, err := stty("-g")
+	if err != nil {
+		return err
+	}
+	// Hold back what the loaders log until the terminal is restored, so
+	// that it does not tear through the screen.
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer func() {
+		log.SetOutput(os.Stderr)
+		os.Stderr.Write(logged.Bytes())
+	}()
+	if _, err := stty("raw", "-echo"); err != nil {
+		return err
+	}
+	defer stty(strings.TrimSpace(saved))
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	defer fmt.Print("\x1b[?25h\x1b[?1049l")
+
+	b := &browser{
+		client:  client,
+		org:     org,
+		repo:    repo,
+		opts:    opts,
+		sortCol: colCount,
+		desc:    true,
+		quota:   "API ?/?",
+		results: make(chan loadResult),
+	}
+	b.tabs = []*tab{
+		{name: "commits", unit: "commits", load: func() (byCount, error) {
+			return commitStats(client, org, repo, opts)
+		}},
+		{name: "open PRs", unit: "PRs", load: func() (byCount, error) {
+			return prStats(client, org, repo, "open", opts)
+		}},
+		{name: "closed PRs", unit: "PRs", load: func() (byCount, error) {
+			return prStats(client, org, repo, "closed", opts)
+		}},
+		{name: "top contributors", unit: "commits", load: func() (byCount, error) {
+			return topStats(client, org, repo, opts)
+		}},
+	}
+
+	keys := make(chan string)
+	go readKeys(keys)
+	quota := make(chan string)
+	go pollQuota(client, quota)
+
+	b.show(0)
+	for {
+		b.draw()
+		select {
+		case k, ok := <-keys:
+			if !ok || !b.key(k) {
+				return nil
+			}
+		case r := <-b.results:
+			r.t.loading = false
+			r.t.loaded = r.err == nil
+			r.t.rows, r.t.err = r.rows, r.err
+		case q := <-quota:
+			b.quota = q
+		}
+	}
+}
+
+// topStats converts the contributor statistics of a repository into stats
+// whose items are the weeks the contributor committed in, clipped to the
+// weeks between opts.Since and opts.Until.
+func topStats(client *github.Client, org, repo string, opts Options) (byCount, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	stats, _, err := client.Repositories.ListContributorsStats(ctx, org, repo)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]*stat)
+	for _, i := range stats {
+		u := &github.User{ID: i.Author.ID, Login: i.Author.Login, Type: i.Author.Type}
+		s := opts.accountStat(u)
+		s.Count = i.GetTotal()
+		if !opts.Since.IsZero() || !opts.Until.IsZero() {
+			s.Count = 0
+			for _, wk := range opts.clipWeeks(i.Weeks) {
+				s.Count += wk.GetCommits()
+			}
+			if s.Count == 0 {
+				continue
+			}
+		}
+		for _, wk := range opts.clipWeeks(i.Weeks) {
+			if wk.GetCommits() == 0 {
+				continue
+			}
+			s.Dates = append(s.Dates, wk.Week.Time)
+			s.Items = append(s.Items, item{
+				Date:  wk.Week.Time,
+				Ref:   "week",
+				Title: fmt.Sprintf("%d commits, +%d -%d", wk.GetCommits(), wk.GetAdditions(), wk.GetDeletions()),
+			})
+		}
+		m[accountKey(u)] = s
+	}
+	b := ranked(m)
+	opts.trackLogins(b)
+	return b, nil
+}
+
+// stty runs stty against the controlling terminal and returns its output.
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("stty %s: %v", strings.Join(args, " "), err)
+	}
+	return string(out), nil
+}
+
+// readKeys sends every chunk read from stdin as a key. Escape sequences
+// for the arrow keys arrive in a single read.
+func readKeys(keys chan<- string) {
+	buf := make([]byte, 32)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			close(keys)
+			return
+		}
+		keys <- string(buf[:n])
+	}
+}
+
+// pollQuota periodically sends the remaining core API quota.
+func pollQuota(client *github.Client, quota chan<- string) {
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		r, _, err := client.RateLimits(ctx)
+		cancel()
+		if err == nil {
+			quota <- fmt.Sprintf("API %d/%d", r.Core.Remaining, r.Core.Limit)
+		}
+		time.Sleep(quotaInterval)
+	}
+}
+
+// show switches to tab i, loading its rows if needed.
+func (b *browser) show(i int) {
+	b.cur = (i + len(b.tabs)) % len(b.tabs)
+	b.sel, b.top = 0, 0
+	b.detail = nil
+	t := b.tabs[b.cur]
+	if t.loaded || t.loading {
+		return
+	}
+	t.loading = true
+	t.err = nil
+	go func() {
+		rows, err := t.load()
+		rows, _ = b.opts.filterBots(rows)
+		rows = b.opts.redact(rows)
+		if err == nil {
+			rows, err = enrich(b.client, rows, b.opts)
+		}
+		b.results <- loadResult{t: t, rows: rows, err: err}
+	}()
+}
+
+// visible returns the rows of the current tab matching the search query,
+// in the selected order.
+func (b *browser) visible() byCount {
+	var rows byCount
+	for _, s := range b.tabs[b.cur].rows {
+		if b.query == "" || fuzzy(b.query, s.Login) || fuzzy(b.query, strings.Join(s.Email, " ")) {
+			rows = append(rows, s)
+		}
+	}
+	less := func(a, c stat) bool {
+		switch b.sortCol {
+		case colRank:
+			return a.Rank < c.Rank
+		case colLogin:
+			return strings.ToLower(a.Login) < strings.ToLower(c.Login)
+		case colEmail:
+			return strings.Join(a.Email, " ") < strings.Join(c.Email, " ")
+		}
+		return a.Count < c.Count
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if b.desc {
+			return less(rows[j], rows[i])
+		}
+		return less(rows[i], rows[j])
+	})
+	return rows
+}
+
+// fuzzy reports whether the runes of pattern appear in order in s,
+// ignoring case.
+func fuzzy(pattern, s string) bool {
+	s = strings.ToLower(s)
+	for _, r := range strings.ToLower(pattern) {
+		i := strings.IndexRune(s, r)
+		if i < 0 {
+			return false
+		}
+		s = s[i+utf8.RuneLen(r):]
+	}
+	return true
+}
+
+// key handles a key press and reports whether the browser should keep
+// running.
+func (b *browser) key(k string) bool {
+	if k == "\x03" {
+		return false
+	}
+	if b.searching {
+		switch k {
+		case "\r", "\n":
+			b.searching = false
+		case "\x1b":
+			b.searching = false
+			b.query = ""
+		case "\x7f", "\b":
+			if b.query != "" {
+				_, n := utf8.DecodeLastRuneInString(b.query)
+				b.query = b.query[:len(b.query)-n]
+			}
+		default:
+			if utf8.ValidString(k) && !strings.ContainsAny(k, "\x1b\r\n\t") {
+				b.query += k
+			}
+		}
+		b.sel, b.top = 0, 0
+		return true
+	}
+	if b.detail != nil {
+		switch k {
+		case "q", "\x1b", "\x7f", "\b", "\x1b[D", "h":
+			b.detail = nil
+		case "\x1b[A", "k":
+			if b.detailTop > 0 {
+				b.detailTop--
+			}
+		case "\x1b[B", "j":
+			if b.detailTop < len(b.detail.Items)-1 {
+				b.detailTop++
+			}
+		}
+		return true
+	}
+
+	rows := b.visible()
+	page := b.height - 6
+	switch k {
+	case "q":
+		return false
+	case "\t", "\x1b[C", "l":
+		b.show(b.cur + 1)
+	case "\x1b[Z", "\x1b[D", "h":
+		b.show(b.cur - 1)
+	case "1", "2", "3", "4":
+		b.show(int(k[0] - '1'))
+	case "\x1b[A", "k":
+		b.sel--
+	case "\x1b[B", "j":
+		b.sel++
+	case "\x1b[5~":
+		b.sel -= page
+	case "\x1b[6~":
+		b.sel += page
+	case "s":
+		b.sortCol = (b.sortCol + 1) % numCols
+	case "S":
+		b.desc = !b.desc
+	case "/":
+		b.searching = true
+	case "\x1b":
+		b.query = ""
+	case "\r", "\n":
+		if b.sel < len(rows) {
+			s := rows[b.sel]
+			sort.Slice(s.Items, func(i, j int) bool { return s.Items[i].Date.After(s.Items[j].Date) })
+			b.detail = &s
+			b.detailTop = 0
+		}
+	}
+	if b.sel >= len(rows) {
+		b.sel = len(rows) - 1
+	}
+	if b.sel < 0 {
+		b.sel = 0
+	}
+	return true
+}
+
+// size updates the terminal dimensions.
+func (b *browser) size() {
+	b.width, b.height = 80, 24
+	out, err := stty("size")
+	if err != nil {
+		return
+	}
+	f := strings.Fields(out)
+	if len(f) != 2 {
+		return
+	}
+	if h, err := strconv.Atoi(f[0]); err == nil && h > 0 {
+		b.height = h
+	}
+	if w, err := strconv.Atoi(f[1]); err == nil && w > 0 {
+		b.width = w
+	}
+}
+
+// fit pads or truncates s to exactly w columns, none when w is negative.
+func fit(s string, w int) string {
+	if w < 0 {
+		w = 0
+	}
+	n := utf8.RuneCountInString(s)
+	if n > w {
+		return string([]rune(s)[:w])
+	}
+	return s + strings.Repeat(" ", w-n)
+}
+
+// draw repaints the whole screen.
+func (b *browser) draw() {
+	b.size()
+	var lines []string
+
+	title := fmt.Sprintf(" scrape  %s/%s", b.org, b.repo)
+	lines = append(lines, "\x1b[1m"+fit(title, b.width-len(b.quota)-1)+b.quota+" \x1b[0m")
+
+	var tabs string
+	for i, t := range b.tabs {
+		name := fmt.Sprintf(" %d %s ", i+1, t.name)
+		if i == b.cur {
+			name = "\x1b[7m" + name + "\x1b[0m"
+		}
+		tabs += name + " "
+	}
+	lines = append(lines, tabs)
+
+	if b.detail != nil {
+		lines = append(lines, b.drawDetail()...)
+	} else {
+		lines = append(lines, b.drawTable()...)
+	}
+
+	for len(lines) < b.height-1 {
+		lines = append(lines, "")
+	}
+	lines = lines[:b.height-1]
+	lines = append(lines, "\x1b[2m"+fit(" "+tuiHelp, b.width)+"\x1b[0m")
+	fmt.Print("\x1b[H\x1b[2J" + strings.Join(lines, "\r\n"))
+}
+
+// drawTable renders the rows of the current tab.
+func (b *browser) drawTable() []string {
+	t := b.tabs[b.cur]
+	order := "↑"
+	if b.desc {
+		order = "↓"
+	}
+	status := fmt.Sprintf(" sort: %s %s", colNames[b.sortCol], order)
+	if b.searching || b.query != "" {
+		status += "   search: " + b.query
+		if b.searching {
+			status += "█"
+		}
+	}
+	lines := []string{status}
+
+	switch {
+	case t.loading:
+		return append(lines, "", " loading "+t.name+"...")
+	case t.err != nil:
+		return append(lines, "", fmt.Sprintf(" error loading %s: %v", t.name, t.err))
+	}
+
+	rows := b.visible()
+	loginW, countW := 24, 8
+	emailW := b.width - loginW - countW - 10
+	if emailW < 10 {
+		emailW = 10
+	}
+	lines = append(lines, "\x1b[4m"+fit(fmt.Sprintf(" %-6s%s %s %*s", "rank", fit("login", loginW), fit("emails", emailW), countW, t.unit), b.width)+"\x1b[0m")
+
+	page := b.height - 6
+	if b.sel < b.top {
+		b.top = b.sel
+	}
+	if b.sel >= b.top+page {
+		b.top = b.sel - page + 1
+	}
+	for i := b.top; i < len(rows) && i < b.top+page; i++ {
+		s := rows[i]
+		line := fit(fmt.Sprintf(" %-6d%s %s %*d", s.Rank, fit(s.who(), loginW), fit(strings.Join(s.Email, ", "), emailW), countW, s.Count), b.width)
+		if i == b.sel {
+			line = "\x1b[7m" + line + "\x1b[0m"
+		}
+		lines = append(lines, line)
+	}
+	if len(rows) == 0 {
+		lines = append(lines, " no matching contributors")
+	}
+	return lines
+}
+
+// drawDetail renders the individual contributions behind the selected row.
+func (b *browser) drawDetail() []string {
+	t := b.tabs[b.cur]
+	s := b.detail
+	lines := []string{
+		fmt.Sprintf(" %s: %d %s", s.who(), s.Count, t.unit),
+		fit(" "+strings.Join(s.Email, ", "), b.width),
+	}
+	if p := s.Profile; p != nil {
+		lines = append(lines, fit(fmt.Sprintf(" %s  %s  %s  joined %s  %d followers",
+			p.Name, p.Company, p.Location, p.CreatedAt.Format("2006-01-02"), p.Followers), b.width))
+	}
+	page := b.height - 5
+	for i := b.detailTop; i < len(s.Items) && i < b.detailTop+page; i++ {
+		it := s.Items[i]
+		lines = append(lines, fit(fmt.Sprintf(" %s  %-8s %s", it.Date.Format("2006-01-02"), it.Ref, it.Title), b.width))
+	}
+	return lines
+}
@@ -0,0 +1,292 @@
+package scrape
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// UserKinds are the kinds of contributions of a user report, in the order
+// they are shown.
+var UserKinds = []string{"commits", "PRs opened", "PRs merged", "reviews", "issues"}
+
+// searchRange formats the window from since up to until for a search
+// qualifier, whose ranges include their end, open ended when either is
+// zero.
+func searchRange(since, until time.Time) string {
+	from, to := "*", "*"
+	if !since.IsZero() {
+		from = since.Format("2006-01-02")
+	}
+	if !until.IsZero() {
+		to = until.Add(-time.Nanosecond).Format("2006-01-02")
+	}
+	return from + ".." + to
+}
+
+// repoOfURL returns the owner/name of the repository a github.com URL,
+// such as the one of an issue, points into.
+func repoOfURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil {
+		return ""
+	}
+	parts := strings.SplitN(strings.Trim(u.Path, "/"), "/", 3)
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[0] + "/" + parts[1]
+}
+
+// warnTruncated logs a warning when a search returned fewer results than
+// it matched, as the search API stops at 1000 results and may give up on
+// slow queries.
+func warnTruncated(query string, total, got int, incomplete bool) {
+	if total > got || incomplete {
+		log.Printf("warning: search %q matched %d results but only returned %d, the counts are incomplete", query, total, got)
+	}
+}
+
+// searchIssues returns the issues and PRs matching an issue search query.
+func searchIssues(client *github.Client, query string) ([]github.Issue, error) {
+	opt := &github.SearchOptions{
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+	var all []github.Issue
+	total, incomplete := 0, false
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		res, resp, err := client.Search.Issues(ctx, query, opt)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, res.Issues...)
+		total = res.GetTotal()
+		incomplete = incomplete || res.GetIncompleteResults()
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.ListOptions.Page = resp.NextPage
+	}
+	warnTruncated(query, total, len(all), incomplete)
+	return all, nil
+}
+
+// searchCommits returns the commits matching a commit search query.
+func searchCommits(client *github.Client, query string) ([]*github.CommitResult, error) {
+	opt := &github.SearchOptions{
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+	var all []*github.CommitResult
+	total, incomplete := 0, false
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		res, resp, err := client.Search.Commits(ctx, query, opt)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, res.Commits...)
+		total = res.GetTotal()
+		incomplete = incomplete || res.GetIncompleteResults()
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.ListOptions.Page = resp.NextPage
+	}
+	warnTruncated(query, total, len(all), incomplete)
+	return all, nil
+}
+
+// reviewEvents returns the PRs login reviewed according to their recent
+// public events, which GitHub keeps for 90 days. Unlike the search API
+// they tell when the review happened.
+func reviewEvents(client *github.Client, login string, since, until time.Time) ([]item, error) {
+	opt := &github.ListOptions{PerPage: 100}
+	var reviews []item
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		events, resp, err := client.Activity.ListEventsPerformedByUser(ctx, login, true, opt)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range events {
+			d := e.GetCreatedAt()
+			if e.GetType() != "PullRequestReviewEvent" || d.Before(since) || !until.IsZero() && !d.Before(until) {
+				continue
+			}
+			var p struct {
+				PullRequest struct {
+					Number int    `json:"number"`
+					Title  string `json:"title"`
+				} `json:"pull_request"`
+			}
+			if e.RawPayload == nil || json.Unmarshal(*e.RawPayload, &p) != nil {
+				continue
+			}
+			reviews = append(reviews, item{
+				Date:  d,
+				Ref:   fmt.Sprintf("#%d", p.PullRequest.Number),
+				Title: p.PullRequest.Title,
+				Repo:  e.Repo.GetName(),
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return reviews, nil
+}
+
+// userContributions returns the contributions of login between opts.Since
+// and opts.Until per kind, see UserKinds. Reviews are the PRs of others
+// reviewed by login and updated in the window, along with the reviews in
+// its recent events.
+func userContributions(client *github.Client, login string, opts Options) (map[string][]item, error) {
+	window := searchRange(opts.Since, opts.Until)
+	m := make(map[string][]item)
+
+	commits, err := searchCommits(client, fmt.Sprintf("author:%s author-date:%s", login, window))
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range commits {
+		it := item{Ref: c.GetSHA(), Repo: c.Repository.GetFullName()}
+		if c.Commit != nil {
+			it.Title = firstLine(c.Commit.GetMessage())
+			if c.Commit.Author != nil {
+				it.Date = c.Commit.Author.GetDate()
+			}
+		}
+		if len(it.Ref) > 7 {
+			it.Ref = it.Ref[:7]
+		}
+		m["commits"] = append(m["commits"], it)
+	}
+
+	queries := []struct{ kind, query string }{
+		{"PRs opened", "type:pr author:%s created:%s"},
+		{"PRs merged", "type:pr author:%s merged:%s"},
+		{"reviews", "type:pr reviewed-by:%[1]s -author:%[1]s updated:%[2]s"},
+		{"issues", "type:issue author:%s created:%s"},
+	}
+	seen := make(map[string]bool)
+	for _, q := range queries {
+		issues, err := searchIssues(client, fmt.Sprintf(q.query, login, window))
+		if err != nil {
+			return nil, err
+		}
+		for _, i := range issues {
+			it := item{Date: i.GetCreatedAt(), Ref: fmt.Sprintf("#%d", i.GetNumber()), Title: i.GetTitle(), Repo: repoOfURL(i.GetHTMLURL())}
+			switch q.kind {
+			case "PRs merged":
+				if i.ClosedAt != nil {
+					it.Date = *i.ClosedAt
+				}
+			case "reviews":
+				it.Date = i.GetUpdatedAt()
+				seen[it.Repo+it.Ref] = true
+			}
+			m[q.kind] = append(m[q.kind], it)
+		}
+	}
+
+	reviews, err := reviewEvents(client, login, opts.Since, opts.Until)
+	if err != nil {
+		return nil, err
+	}
+	for _, it := range reviews {
+		if !seen[it.Repo+it.Ref] {
+			seen[it.Repo+it.Ref] = true
+			m["reviews"] = append(m["reviews"], it)
+		}
+	}
+	return m, nil
+}
+
+// UserReport prints to stdout the contributions of login across all
+// repositories between opts.Since and opts.Until, per repository. With
+// list every contribution is listed as well.
+func UserReport(client *github.Client, login string, list bool, opts Options) error {
+	m, err := userContributions(client, login, opts)
+	if err != nil {
+		return err
+	}
+	counts := make(map[string]map[string]int)
+	for kind, items := range m {
+		for _, it := range items {
+			if counts[it.Repo] == nil {
+				counts[it.Repo] = make(map[string]int)
+			}
+			counts[it.Repo][kind]++
+		}
+	}
+	var repos []string
+	for r := range counts {
+		repos = append(repos, r)
+	}
+	total := func(r string) int {
+		n := 0
+		for _, c := range counts[r] {
+			n += c
+		}
+		return n
+	}
+	sort.Slice(repos, func(i, j int) bool {
+		if total(repos[i]) != total(repos[j]) {
+			return total(repos[i]) < total(repos[j])
+		}
+		return repos[i] > repos[j]
+	})
+
+	fmt.Printf("%s, %s\n\n", login, strings.Replace(searchRange(opts.Since, opts.Until), "..", " to ", 1))
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 10, 8, 0, '\t', 0)
+	fmt.Fprintln(w, "repo\t"+strings.Join(UserKinds, "\t"))
+	for _, r := range repos {
+		row := r
+		for _, kind := range UserKinds {
+			row += fmt.Sprintf("\t%d", counts[r][kind])
+		}
+		fmt.Fprintln(w, row)
+	}
+	fmt.Fprintln(w)
+	w.Flush()
+	for _, kind := range UserKinds {
+		fmt.Printf("TOTAL %s: %d\n", strings.ToUpper(kind), len(m[kind]))
+	}
+	fmt.Printf("TOTAL REPOSITORIES: %d\n", len(repos))
+
+	if !list {
+		return nil
+	}
+	for n := len(repos) - 1; n >= 0; n-- {
+		fmt.Printf("\n%s\n", repos[n])
+		for _, kind := range UserKinds {
+			items := m[kind]
+			sort.Slice(items, func(i, j int) bool { return items[i].Date.After(items[j].Date) })
+			for _, it := range items {
+				if it.Repo == repos[n] {
+					fmt.Printf("  %s  %-10s  %-8s %s\n", it.Date.Format("2006-01-02"), kind, it.Ref, it.Title)
+				}
+			}
+		}
+	}
+	return nil
+}
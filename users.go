@@ -0,0 +1,193 @@
+package scrape
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// profileTTL is how long a cached profile is used before it is fetched
+// again.
+const profileTTL = 7 * 24 * time.Hour
+
+// searchInterval paces search API requests to the 30 per minute it allows.
+const searchInterval = time.Minute / 30
+
+// resolveEmails looks up the GitHub account of every email in emails with
+// the user search API and returns the accounts found by email. The search
+// only matches emails made public on a profile. Emails matching no
+// account, or several, are left out. On error it returns the accounts
+// found until then.
+func resolveEmails(client *github.Client, emails []string) (map[string]*github.User, error) {
+	found := make(map[string]*github.User)
+	for n, e := range emails {
+		if n > 0 {
+			time.Sleep(searchInterval)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		res, _, err := client.Search.Users(ctx, e+" in:email", nil)
+		if err != nil {
+			return found, err
+		}
+		if len(res.Users) == 1 {
+			found[e] = &res.Users[0]
+		}
+	}
+	return found, nil
+}
+
+// profile is the part of a GitHub user profile shown by Options.Enrich.
+type profile struct {
+	ID        int       `json:"id"`
+	Login     string    `json:"login"`
+	Name      string    `json:"name"`
+	Company   string    `json:"company"`
+	Location  string    `json:"location"`
+	CreatedAt time.Time `json:"created_at"`
+	Followers int       `json:"followers"`
+	Fetched   time.Time `json:"fetched"`
+}
+
+// profileCache keeps the profiles fetched by previous runs on disk.
+type profileCache struct {
+	path  string
+	Users map[string]*profile `json:"users"`
+}
+
+// defaultCacheDir returns the directory scrape keeps its caches in.
+func defaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "scrape")
+}
+
+// openProfileCache loads the profile cache in dir, the default cache
+// directory when empty. A missing cache is not an error.
+func openProfileCache(dir string) (*profileCache, error) {
+	if dir == "" {
+		dir = defaultCacheDir()
+	}
+	c := &profileCache{
+		path:  filepath.Join(dir, "users.json"),
+		Users: make(map[string]*profile),
+	}
+	b, err := ioutil.ReadFile(c.path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("%s: %v", c.path, err)
+	}
+	if c.Users == nil {
+		c.Users = make(map[string]*profile)
+	}
+	return c, nil
+}
+
+// get returns the profile of login, fetching it when it is not cached or
+// the cached copy is older than profileTTL.
+func (c *profileCache) get(client *github.Client, login string) (*profile, error) {
+	key := strings.ToLower(login)
+	if p, ok := c.Users[key]; ok && time.Since(p.Fetched) < profileTTL {
+		return p, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	u, _, err := client.Users.Get(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+	p := &profile{
+		ID:        u.GetID(),
+		Login:     u.GetLogin(),
+		Name:      u.GetName(),
+		Company:   normalizeCompany(u.GetCompany()),
+		Location:  u.GetLocation(),
+		Followers: u.GetFollowers(),
+		Fetched:   time.Now(),
+	}
+	if u.CreatedAt != nil {
+		p.CreatedAt = u.CreatedAt.Time
+	}
+	c.Users[key] = p
+	return p, nil
+}
+
+// save writes the cache back to disk.
+func (c *profileCache) save() error {
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.path, b, 0644)
+}
+
+// hasProfile reports whether s is a GitHub account with a profile.
+func hasProfile(s stat) bool {
+	return !s.Unlinked && s.Login != "username missing"
+}
+
+// profiles fetches the profile of every account in b through the cache in
+// opts.CacheDir, keyed by login.
+func profiles(client *github.Client, b byCount, opts Options) (map[string]*profile, error) {
+	c, err := openProfileCache(opts.CacheDir)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]*profile)
+	for _, s := range b {
+		if !hasProfile(s) {
+			continue
+		}
+		if m[s.Login], err = c.get(client, s.Login); err != nil {
+			c.save()
+			return nil, err
+		}
+	}
+	return m, c.save()
+}
+
+// enrich attaches the GitHub profile of every account in b when
+// opts.Enrich is set.
+func enrich(client *github.Client, b byCount, opts Options) (byCount, error) {
+	if !opts.Enrich {
+		return b, nil
+	}
+	m, err := profiles(client, b, opts)
+	if err != nil {
+		return nil, err
+	}
+	for n := range b {
+		b[n].Profile = m[b[n].Login]
+	}
+	return b, nil
+}
+
+// profileHeader is the header of the columns added by profileColumns.
+const profileHeader = "\tname\tcompany\tlocation\tcreated\tfollowers"
+
+// profileColumns returns the profile columns of s, empty for authors
+// without a GitHub account.
+func profileColumns(s stat) string {
+	p := s.Profile
+	if p == nil {
+		return "\t\t\t\t\t"
+	}
+	return fmt.Sprintf("\t%s\t%s\t%s\t%s\t%d", p.Name, p.Company, p.Location, p.CreatedAt.Format("2006-01-02"), p.Followers)
+}